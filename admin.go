@@ -0,0 +1,40 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// adminAPIKey is the key admin requests must send, loaded from
+// ADMIN_API_KEY
+var adminAPIKey string
+
+// requireAdminKey rejects requests whose X-Admin-Key header does not match
+// the configured admin API key
+func requireAdminKey() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader("X-Admin-Key")
+		if subtle.ConstantTimeCompare([]byte(key), []byte(adminAPIKey)) != 1 {
+			c.Abort()
+			respondJSON(c, http.StatusUnauthorized, gin.H{"error": "Invalid admin key"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// ResetTodos removes all todos and restarts ID assignment
+func ResetTodos(c *gin.Context) {
+	todoMu.Lock()
+	defer todoMu.Unlock()
+
+	removed := len(todos)
+	seedTodos(nil)
+
+	respondJSON(c, http.StatusOK, gin.H{
+		"message": "Datastore reset successfully",
+		"removed": removed,
+	})
+}
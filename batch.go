@@ -0,0 +1,272 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BatchGetRequest is the body accepted when fetching or checking several
+// todos by ID
+type BatchGetRequest struct {
+	IDs []int `json:"ids" binding:"required,max=100,dive,gt=0"`
+}
+
+// TagRequest is the body accepted when adding and removing tags on several
+// todos at once
+type TagRequest struct {
+	IDs    []int    `json:"ids" binding:"required,max=100,dive,gt=0"`
+	Add    []string `json:"add"`
+	Remove []string `json:"remove"`
+}
+
+// uniqueIDs returns ids without repeats, keeping the first occurrence of
+// each so the mutating batch endpoints handle and report every ID once
+func uniqueIDs(ids []int) []int {
+	unique := []int{}
+	seen := map[int]bool{}
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// BatchGetTodos returns the requested todos in request order, with null in
+// place of each ID that does not exist so results line up by index with
+// the requested IDs, repeats included
+func BatchGetTodos(c *gin.Context) {
+	var req BatchGetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondJSON(c, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	todoMu.RLock()
+	defer todoMu.RUnlock()
+
+	results := make([]*Todo, len(req.IDs))
+	missing := []int{}
+	for i, id := range req.IDs {
+		if j := findTodoIndex(id); j != -1 {
+			todo := todos[j]
+			results[i] = &todo
+		} else {
+			missing = append(missing, id)
+		}
+	}
+
+	respondJSON(c, http.StatusOK, gin.H{listKey: results, "missing_ids": missing})
+}
+
+// GetTodoStatuses returns the completed flag of each requested todo that
+// exists, keyed by ID, and the IDs that do not exist
+func GetTodoStatuses(c *gin.Context) {
+	var req BatchGetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondJSON(c, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	wanted := map[int]bool{}
+	for _, id := range req.IDs {
+		wanted[id] = true
+	}
+
+	todoMu.RLock()
+	statuses := map[int]bool{}
+	for _, todo := range todos {
+		if wanted[todo.ID] {
+			statuses[todo.ID] = todo.Completed
+		}
+	}
+	todoMu.RUnlock()
+
+	missing := []int{}
+	for _, id := range req.IDs {
+		if _, ok := statuses[id]; !ok && !slices.Contains(missing, id) {
+			missing = append(missing, id)
+		}
+	}
+
+	respondJSON(c, http.StatusOK, gin.H{"statuses": statuses, "missing_ids": missing})
+}
+
+// ToggleTodos flips the completed flag of each requested todo that exists,
+// once per ID even when it is repeated, and returns the updated todos and
+// the IDs that do not exist. With requireSubtasksComplete set, nothing is toggled if a todo would be
+// completed while a subtask stays incomplete. A dry run returns the todos
+// as they would be toggled without storing them.
+func ToggleTodos(c *gin.Context) {
+	var req BatchGetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondJSON(c, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	req.IDs = uniqueIDs(req.IDs)
+
+	todoMu.Lock()
+	defer todoMu.Unlock()
+
+	if requireSubtasksComplete {
+		toggled := map[int]bool{}
+		for _, id := range req.IDs {
+			toggled[id] = true
+		}
+		for _, id := range req.IDs {
+			i := findTodoIndex(id)
+			if i != -1 && !todos[i].Completed && hasPendingSubtasks(id, toggled) {
+				respondJSON(c, http.StatusConflict, gin.H{"error": fmt.Sprintf("Todo %d has incomplete subtasks", id)})
+				return
+			}
+		}
+	}
+
+	now := time.Now()
+	indexes := []int{}
+	updated := []Todo{}
+	missing := []int{}
+	for _, id := range req.IDs {
+		i := findTodoIndex(id)
+		if i == -1 {
+			missing = append(missing, id)
+			continue
+		}
+
+		todo := todos[i]
+		todo.Completed = !todo.Completed
+		if autoCompleteOnProgress {
+			// Keep progress consistent so the flag is not immediately
+			// derived back from it
+			if todo.Completed {
+				todo.Progress = 100
+			} else if todo.Progress == 100 {
+				todo.Progress = 0
+			}
+		}
+		todo.UpdatedAt = now
+		trackCompletion(&todo, !todo.Completed, now)
+		indexes = append(indexes, i)
+		updated = append(updated, todo)
+	}
+
+	if isDryRun(c) {
+		respondJSON(c, http.StatusOK, gin.H{"dry_run": true, listKey: updated, "missing_ids": missing})
+		return
+	}
+
+	for n, i := range indexes {
+		todos[i] = updated[n]
+		publishChange(eventTodoUpdated, updated[n])
+	}
+	if len(updated) > 0 {
+		invalidateListCache()
+	}
+
+	respondJSON(c, http.StatusOK, gin.H{listKey: updated, "missing_ids": missing})
+}
+
+// TagTodos adds and removes tags on each requested todo that exists, and
+// returns those todos and the IDs that do not exist. Tags are normalized
+// like on create; a tag both added and removed ends up added. Nothing is
+// changed if any todo would end up with more than MAX_TAGS tags, or if
+// LOCK_COMPLETED is set and a completed todo's tags would change. A dry run
+// returns the todos as they would be retagged without storing them.
+func TagTodos(c *gin.Context) {
+	var req TagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondJSON(c, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	add, err := normalizeTags(req.Add)
+	if err != nil {
+		respondJSON(c, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	remove, err := normalizeTags(req.Remove)
+	if err != nil {
+		respondJSON(c, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(add) == 0 && len(remove) == 0 {
+		respondJSON(c, http.StatusBadRequest, gin.H{"error": "add or remove must list at least one tag"})
+		return
+	}
+	req.IDs = uniqueIDs(req.IDs)
+
+	todoMu.Lock()
+	defer todoMu.Unlock()
+
+	indexes := []int{}
+	retagged := [][]string{}
+	missing := []int{}
+	for _, id := range req.IDs {
+		i := findTodoIndex(id)
+		if i == -1 {
+			missing = append(missing, id)
+			continue
+		}
+
+		tags := []string{}
+		for _, tag := range todos[i].Tags {
+			if !slices.Contains(remove, tag) || slices.Contains(add, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		for _, tag := range add {
+			if !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		if len(tags) > maxTags {
+			respondJSON(c, http.StatusBadRequest, gin.H{"error": fmt.Sprintf("todo %d would have too many tags: at most %d allowed", id, maxTags)})
+			return
+		}
+		if lockCompleted && todos[i].Completed && !slices.Equal(todos[i].Tags, tags) {
+			respondJSON(c, http.StatusConflict, gin.H{"error": fmt.Sprintf("todo %d is completed and locked", id)})
+			return
+		}
+		indexes = append(indexes, i)
+		retagged = append(retagged, tags)
+	}
+
+	now := time.Now()
+	updated := []Todo{}
+	changed := []bool{}
+	for n, i := range indexes {
+		todo := todos[i]
+		differs := !slices.Equal(todo.Tags, retagged[n])
+		if differs {
+			todo.Tags = retagged[n]
+			if len(todo.Tags) == 0 {
+				todo.Tags = nil
+			}
+			todo.UpdatedAt = now
+		}
+		updated = append(updated, todo)
+		changed = append(changed, differs)
+	}
+
+	if isDryRun(c) {
+		respondJSON(c, http.StatusOK, gin.H{"dry_run": true, listKey: updated, "missing_ids": missing})
+		return
+	}
+
+	for n, i := range indexes {
+		if changed[n] {
+			todos[i] = updated[n]
+			publishChange(eventTodoUpdated, updated[n])
+		}
+	}
+	if slices.Contains(changed, true) {
+		invalidateListCache()
+	}
+
+	respondJSON(c, http.StatusOK, gin.H{listKey: updated, "missing_ids": missing})
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+)
+
+// batchResponse is the body of the batch toggle and tag endpoints
+type batchResponse struct {
+	DryRun     bool   `json:"dry_run"`
+	Todos      []Todo `json:"todos"`
+	MissingIDs []int  `json:"missing_ids"`
+}
+
+func TestToggleTodos(t *testing.T) {
+	r := newTestRouter(t, nil, []Todo{{ID: 1, Title: "First"}, {ID: 2, Title: "Second", Completed: true}})
+
+	w := doRequest(r, http.MethodPost, "/api/v1/todos/toggle", `{"ids":[1,2,99,1]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, body %s", w.Code, w.Body)
+	}
+	var resp batchResponse
+	decodeBody(t, w, &resp)
+	if len(resp.Todos) != 2 || !slices.Equal(resp.MissingIDs, []int{99}) {
+		t.Errorf("response %+v", resp)
+	}
+
+	stored := storedTodos()
+	if !stored[0].Completed || stored[0].CompletedAt == nil {
+		t.Errorf("todo 1 after toggle: %+v", stored[0])
+	}
+	if stored[1].Completed || stored[1].CompletedAt != nil {
+		t.Errorf("todo 2 after toggle: %+v", stored[1])
+	}
+}
+
+func TestToggleTodosDryRun(t *testing.T) {
+	r := newTestRouter(t, nil, []Todo{{ID: 1, Title: "First"}})
+
+	w := doRequest(r, http.MethodPost, "/api/v1/todos/toggle?dry_run=true", `{"ids":[1,2]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, body %s", w.Code, w.Body)
+	}
+	var preview batchResponse
+	decodeBody(t, w, &preview)
+	if !preview.DryRun || len(preview.Todos) != 1 || !preview.Todos[0].Completed || !slices.Equal(preview.MissingIDs, []int{2}) {
+		t.Errorf("preview %+v", preview)
+	}
+	if stored := storedTodos(); stored[0].Completed {
+		t.Error("dry run completed the stored todo")
+	}
+}
+
+func TestTagTodos(t *testing.T) {
+	r := newTestRouter(t, nil, []Todo{
+		{ID: 1, Title: "First", Tags: []string{"someday"}},
+		{ID: 2, Title: "Second", Tags: []string{"home", "someday"}},
+	})
+
+	w := doRequest(r, http.MethodPost, "/api/v1/todos/tag", `{"ids":[1,2,99],"add":["Work","work"],"remove":["someday"]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, body %s", w.Code, w.Body)
+	}
+	var resp batchResponse
+	decodeBody(t, w, &resp)
+	if !slices.Equal(resp.MissingIDs, []int{99}) {
+		t.Errorf("missing IDs %v, want [99]", resp.MissingIDs)
+	}
+
+	stored := storedTodos()
+	if !slices.Equal(stored[0].Tags, []string{"work"}) || !slices.Equal(stored[1].Tags, []string{"home", "work"}) {
+		t.Errorf("tags after retagging: %v and %v", stored[0].Tags, stored[1].Tags)
+	}
+}
+
+func TestTagTodosDryRun(t *testing.T) {
+	r := newTestRouter(t, nil, []Todo{{ID: 1, Title: "First"}})
+
+	w := doRequest(r, http.MethodPost, "/api/v1/todos/tag?dry_run=true", `{"ids":[1],"add":["work"]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, body %s", w.Code, w.Body)
+	}
+	var preview batchResponse
+	decodeBody(t, w, &preview)
+	if !preview.DryRun || len(preview.Todos) != 1 || !slices.Equal(preview.Todos[0].Tags, []string{"work"}) {
+		t.Errorf("preview %+v", preview)
+	}
+	if stored := storedTodos(); stored[0].Tags != nil {
+		t.Errorf("dry run stored tags %v", stored[0].Tags)
+	}
+}
+
+func TestBulkRepeatedIDsProcessedOnce(t *testing.T) {
+	r := newTestRouter(t, nil, []Todo{{ID: 1, Title: "First"}})
+
+	var resp batchResponse
+	decodeBody(t, doRequest(r, http.MethodPost, "/api/v1/todos/toggle", `{"ids":[1,1,1,7,7]}`), &resp)
+	if len(resp.Todos) != 1 || !resp.Todos[0].Completed || !slices.Equal(resp.MissingIDs, []int{7}) {
+		t.Errorf("toggle response %+v", resp)
+	}
+	if stored := storedTodos(); !stored[0].Completed {
+		t.Error("todo listed three times was toggled more than once")
+	}
+
+	decodeBody(t, doRequest(r, http.MethodPost, "/api/v1/todos/tag", `{"ids":[1,1,7,7],"add":["work"]}`), &resp)
+	if len(resp.Todos) != 1 || !slices.Equal(resp.MissingIDs, []int{7}) {
+		t.Errorf("tag response %+v", resp)
+	}
+}
+
+func TestBatchGetTodos(t *testing.T) {
+	r := newTestRouter(t, nil, []Todo{{ID: 1, Title: "First"}, {ID: 2, Title: "Second"}})
+
+	w := doRequest(r, http.MethodPost, "/api/v1/todos/batch-get", `{"ids":[2,2,9,1]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, body %s", w.Code, w.Body)
+	}
+	var resp struct {
+		Todos      []*Todo `json:"todos"`
+		MissingIDs []int   `json:"missing_ids"`
+	}
+	decodeBody(t, w, &resp)
+
+	// Results line up by index with the requested IDs, with null gaps
+	want := []int{2, 2, 0, 1}
+	if len(resp.Todos) != len(want) {
+		t.Fatalf("got %d results, want %d", len(resp.Todos), len(want))
+	}
+	for i, id := range want {
+		switch {
+		case id == 0 && resp.Todos[i] != nil:
+			t.Errorf("result %d = todo %d, want null", i, resp.Todos[i].ID)
+		case id != 0 && (resp.Todos[i] == nil || resp.Todos[i].ID != id):
+			t.Errorf("result %d = %v, want todo %d", i, resp.Todos[i], id)
+		}
+	}
+	if !slices.Equal(resp.MissingIDs, []int{9}) {
+		t.Errorf("missing IDs %v, want [9]", resp.MissingIDs)
+	}
+}
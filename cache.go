@@ -0,0 +1,74 @@
+package main
+
+import (
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listCacheEntry is a serialized GetTodos response
+type listCacheEntry struct {
+	body      []byte
+	expiresAt time.Time
+}
+
+// listCache holds serialized GetTodos responses keyed by query string.
+// It is guarded by todoMu: lookups happen under the read lock while
+// stores and invalidation require the write lock.
+type listCache struct {
+	ttl        time.Duration
+	generation uint64
+	entries    map[string]listCacheEntry
+}
+
+// listResponseCache is disabled (nil) unless LIST_CACHE_TTL is set
+var listResponseCache *listCache
+
+func newListCache(ttl time.Duration) *listCache {
+	return &listCache{ttl: ttl, entries: map[string]listCacheEntry{}}
+}
+
+// get returns a cached body that has not expired. Callers must hold at
+// least the read lock.
+func (lc *listCache) get(key string) ([]byte, bool) {
+	entry, ok := lc.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.body, true
+}
+
+// put stores a body computed at the given generation. The entry is dropped
+// if a mutation happened since, so stale data is never cached. Callers
+// must hold the write lock.
+func (lc *listCache) put(key string, generation uint64, body []byte) {
+	if generation != lc.generation {
+		return
+	}
+	lc.entries[key] = listCacheEntry{body: body, expiresAt: time.Now().Add(lc.ttl)}
+}
+
+// invalidateListCache drops all cached list responses. Callers must hold
+// the write lock.
+func invalidateListCache() {
+	if listResponseCache == nil {
+		return
+	}
+	listResponseCache.generation++
+	listResponseCache.entries = map[string]listCacheEntry{}
+}
+
+// hasDueDates reports whether a list response holds a todo with a due
+// date. Such a response carries time_remaining, which is only correct at
+// the moment it is serialized, so it is never cached.
+func hasDueDates(response gin.H) bool {
+	due := func(todo Todo) bool { return todo.DueDate != nil }
+	switch items := response[listKey].(type) {
+	case []Todo:
+		return slices.ContainsFunc(items, due)
+	case []linkedTodo:
+		return slices.ContainsFunc(items, func(item linkedTodo) bool { return due(item.Todo) })
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestListCacheDropsStalePut(t *testing.T) {
+	lc := newListCache(time.Minute)
+	generation := lc.generation
+
+	lc.put("a", generation, []byte("fresh"))
+	if body, ok := lc.get("a"); !ok || string(body) != "fresh" {
+		t.Fatalf("get after put = %q, %t", body, ok)
+	}
+
+	lc.generation++
+	lc.put("b", generation, []byte("stale"))
+	if _, ok := lc.get("b"); ok {
+		t.Error("put computed before a change was cached")
+	}
+}
+
+func TestListCacheExpires(t *testing.T) {
+	lc := newListCache(time.Nanosecond)
+	lc.put("a", lc.generation, []byte("body"))
+	time.Sleep(time.Millisecond)
+	if _, ok := lc.get("a"); ok {
+		t.Error("expired entry was returned")
+	}
+}
+
+func TestCachedListHit(t *testing.T) {
+	r := newTestRouter(t, map[string]string{"LIST_CACHE_TTL": "1m"}, []Todo{{ID: 1, Title: "First"}})
+
+	first := doRequest(r, http.MethodGet, "/api/v1/todos?page=1", "")
+
+	// Changing the store behind the handlers' back leaves the cache in
+	// place, so a hit still serves the old body
+	todoMu.Lock()
+	todos[0].Title = "Changed"
+	todoMu.Unlock()
+
+	second := doRequest(r, http.MethodGet, "/api/v1/todos?page=1", "")
+	if second.Body.String() != first.Body.String() {
+		t.Errorf("cache hit returned %s, want %s", second.Body, first.Body)
+	}
+	other := doRequest(r, http.MethodGet, "/api/v1/todos?page=1&limit=5", "")
+	if other.Body.String() == first.Body.String() {
+		t.Error("a different query was served from the same entry")
+	}
+}
+
+func TestCachedListInvalidatedByWrites(t *testing.T) {
+	r := newTestRouter(t, map[string]string{"LIST_CACHE_TTL": "1m"}, []Todo{{ID: 1, Title: "First"}})
+
+	var page struct {
+		Todos []Todo `json:"todos"`
+	}
+	decodeBody(t, doRequest(r, http.MethodGet, "/api/v1/todos", ""), &page)
+	if len(page.Todos) != 1 {
+		t.Fatalf("first list has %d todos, want 1", len(page.Todos))
+	}
+
+	if w := doRequest(r, http.MethodPost, "/api/v1/todos", `{"title":"Second"}`); w.Code != http.StatusCreated {
+		t.Fatalf("create: status %d, body %s", w.Code, w.Body)
+	}
+	decodeBody(t, doRequest(r, http.MethodGet, "/api/v1/todos", ""), &page)
+	if len(page.Todos) != 2 {
+		t.Errorf("list after create has %d todos, want 2", len(page.Todos))
+	}
+}
+
+func TestCachedListSkipsDueDates(t *testing.T) {
+	due := time.Now().Add(time.Hour)
+	r := newTestRouter(t, map[string]string{"LIST_CACHE_TTL": "1m"}, []Todo{{ID: 1, Title: "Due", DueDate: &due}})
+
+	remaining := func() float64 {
+		t.Helper()
+		var page struct {
+			Todos []map[string]any `json:"todos"`
+		}
+		decodeBody(t, doRequest(r, http.MethodGet, "/api/v1/todos", ""), &page)
+		return page.Todos[0]["time_remaining"].(float64)
+	}
+
+	first := remaining()
+	// Stand in for the clock moving on by pulling the due date closer
+	// without a write that would clear the cache
+	todoMu.Lock()
+	earlier := due.Add(-30 * time.Minute)
+	todos[0].DueDate = &earlier
+	todoMu.Unlock()
+	if second := remaining(); second > first-1700 {
+		t.Errorf("time_remaining went from %v to %v, want the fresh value", first, second)
+	}
+}
+
+func TestCachedListKeepsContentType(t *testing.T) {
+	r := newTestRouter(t, map[string]string{"LIST_CACHE_TTL": "1m"}, []Todo{{ID: 1, Title: "First"}})
+
+	for i := 0; i < 2; i++ {
+		w := doRequest(r, http.MethodGet, "/api/v1/todos", "", "Accept", "application/xml")
+		if got := w.Header().Get("Content-Type"); got != "application/xml; charset=utf-8" {
+			t.Errorf("request %d: Content-Type %q, want XML", i+1, got)
+		}
+	}
+	w := doRequest(r, http.MethodGet, "/api/v1/todos", "")
+	if got := w.Header().Get("Content-Type"); got != "application/json; charset=utf-8" {
+		t.Errorf("JSON request: Content-Type %q", got)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// icsTimeFormat is the UTC date-time form used by iCalendar (RFC 5545)
+const icsTimeFormat = "20060102T150405Z"
+
+// icsEscape escapes a text value per RFC 5545 section 3.3.11
+func icsEscape(value string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	)
+	return replacer.Replace(value)
+}
+
+// icsFold writes a content line, folding it so no physical line exceeds
+// 75 octets as RFC 5545 requires, without splitting multi-byte characters.
+// Continuation lines carry one fewer octet of content to leave room for
+// their leading space.
+func icsFold(b *strings.Builder, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = 74
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+// GetTodosCalendar returns todos with a due date as an iCalendar feed
+func GetTodosCalendar(c *gin.Context) {
+	todoMu.RLock()
+	defer todoMu.RUnlock()
+
+	var b strings.Builder
+	icsFold(&b, "BEGIN:VCALENDAR")
+	icsFold(&b, "VERSION:2.0")
+	icsFold(&b, "PRODID:-//go-gin-todo-app//Todos//EN")
+	for _, todo := range todos {
+		if todo.DueDate == nil {
+			continue
+		}
+
+		status := "NEEDS-ACTION"
+		if todo.Completed {
+			status = "COMPLETED"
+		}
+
+		icsFold(&b, "BEGIN:VTODO")
+		icsFold(&b, fmt.Sprintf("UID:todo-%d@go-gin-todo-app", todo.ID))
+		icsFold(&b, "DTSTAMP:"+todo.UpdatedAt.UTC().Format(icsTimeFormat))
+		icsFold(&b, "SUMMARY:"+icsEscape(todo.Title))
+		if todo.Description != "" {
+			icsFold(&b, "DESCRIPTION:"+icsEscape(todo.Description))
+		}
+		icsFold(&b, "DUE:"+todo.DueDate.UTC().Format(icsTimeFormat))
+		icsFold(&b, "STATUS:"+status)
+		icsFold(&b, "END:VTODO")
+	}
+	icsFold(&b, "END:VCALENDAR")
+
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(b.String()))
+}
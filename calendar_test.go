@@ -0,0 +1,133 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestICSFold(t *testing.T) {
+	tests := []string{
+		"SUMMARY:short",
+		"SUMMARY:" + strings.Repeat("x", 300),
+		"SUMMARY:" + strings.Repeat("é", 150),
+	}
+	for _, line := range tests {
+		var b strings.Builder
+		icsFold(&b, line)
+		folded := b.String()
+
+		physical := strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n")
+		var unfolded strings.Builder
+		for i, part := range physical {
+			if len(part) > 75 {
+				t.Errorf("line %d of %q is %d octets", i, line[:20], len(part))
+			}
+			if i > 0 {
+				if !strings.HasPrefix(part, " ") {
+					t.Fatalf("continuation line %d does not start with a space", i)
+				}
+				part = part[1:]
+			}
+			if !utf8.ValidString(part) {
+				t.Errorf("line %d splits a character", i)
+			}
+			unfolded.WriteString(part)
+		}
+		if unfolded.String() != line {
+			t.Errorf("unfolding gives %q, want %q", unfolded.String(), line)
+		}
+	}
+}
+
+// parseICS unfolds an iCalendar body and returns its content lines,
+// failing the test on a line not terminated by CRLF
+func parseICS(t *testing.T, body string) []string {
+	t.Helper()
+	if !strings.HasSuffix(body, "\r\n") {
+		t.Fatal("feed does not end with CRLF")
+	}
+	var lines []string
+	for _, physical := range strings.Split(strings.TrimSuffix(body, "\r\n"), "\r\n") {
+		if strings.Contains(physical, "\n") || strings.Contains(physical, "\r") {
+			t.Fatalf("line %q is not terminated by CRLF", physical)
+		}
+		if strings.HasPrefix(physical, " ") && len(lines) > 0 {
+			lines[len(lines)-1] += physical[1:]
+			continue
+		}
+		lines = append(lines, physical)
+	}
+	return lines
+}
+
+func TestGetTodosCalendar(t *testing.T) {
+	due := time.Date(2030, 1, 5, 17, 0, 0, 0, time.UTC)
+	r := newTestRouter(t, nil, []Todo{
+		{ID: 1, Title: "Pay rent; twice, really", Description: "Line one\nline two", DueDate: &due},
+		{ID: 2, Title: "No due date"},
+		{ID: 3, Title: "Done", Completed: true, DueDate: &due},
+	})
+
+	w := doRequest(r, http.MethodGet, "/api/v1/todos/calendar.ics", "")
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/calendar") {
+		t.Fatalf("status %d, Content-Type %q", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	lines := parseICS(t, w.Body.String())
+	if lines[0] != "BEGIN:VCALENDAR" || lines[len(lines)-1] != "END:VCALENDAR" {
+		t.Fatalf("feed is not wrapped in VCALENDAR: %q ... %q", lines[0], lines[len(lines)-1])
+	}
+	if !slices.Contains(lines, "VERSION:2.0") || !slices.ContainsFunc(lines, func(l string) bool { return strings.HasPrefix(l, "PRODID:") }) {
+		t.Error("calendar lacks VERSION or PRODID")
+	}
+
+	// Collect each component's properties, checking BEGIN and END pair up
+	var entries []map[string]string
+	var current map[string]string
+	for _, line := range lines[1 : len(lines)-1] {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			t.Fatalf("line %q has no value", line)
+		}
+		switch {
+		case name == "BEGIN":
+			if current != nil || value != "VTODO" {
+				t.Fatalf("unexpected BEGIN:%s", value)
+			}
+			current = map[string]string{}
+		case name == "END":
+			if current == nil || value != "VTODO" {
+				t.Fatalf("unexpected END:%s", value)
+			}
+			entries = append(entries, current)
+			current = nil
+		case current != nil:
+			current[name] = value
+		}
+	}
+	if current != nil {
+		t.Fatal("unterminated VTODO")
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want one per todo with a due date", len(entries))
+	}
+	want := []map[string]string{
+		{"UID": "todo-1@go-gin-todo-app", "SUMMARY": `Pay rent\; twice\, really`, "DESCRIPTION": `Line one\nline two`, "DUE": "20300105T170000Z", "STATUS": "NEEDS-ACTION"},
+		{"UID": "todo-3@go-gin-todo-app", "SUMMARY": "Done", "DUE": "20300105T170000Z", "STATUS": "COMPLETED"},
+	}
+	for i, entry := range entries {
+		if entry["DTSTAMP"] == "" {
+			t.Errorf("entry %d has no DTSTAMP", i)
+		}
+		for name, value := range want[i] {
+			if entry[name] != value {
+				t.Errorf("entry %d: %s = %q, want %q", i, name, entry[name], value)
+			}
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Change is one entry of the change feed: a todo as it was right after an
+// event, and the revision the event moved the store to
+type Change struct {
+	Revision  uint64    `json:"revision" xml:"revision"`
+	Type      string    `json:"type" xml:"type"`
+	Todo      Todo      `json:"todo" xml:"todo"`
+	Timestamp time.Time `json:"timestamp" xml:"timestamp"`
+}
+
+// The change log keeps the latest changeLogSize changes, loaded from
+// CHANGE_LOG_SIZE. Every change after changeLogStart is in it; older ones
+// were dropped or wiped by a reset. Both are guarded by todoMu.
+var (
+	changeLog      []Change
+	changeLogStart uint64
+	changeLogSize  = 1000
+)
+
+// publishChange records an event in the change log under a new revision
+// and sends it to the webhooks. Callers must hold the write lock.
+func publishChange(eventType string, todo Todo) {
+	revision++
+	changeLog = append(changeLog, Change{Revision: revision, Type: eventType, Todo: todo, Timestamp: time.Now()})
+	if drop := len(changeLog) - changeLogSize; drop > 0 {
+		changeLogStart = changeLog[drop-1].Revision
+		changeLog = append([]Change(nil), changeLog[drop:]...)
+	}
+	webhooks.dispatch(eventType, todo)
+}
+
+// resetChangeLog records that the store was replaced wholesale under a new
+// revision, and empties the change log so clients behind it must refetch.
+// Callers must hold the write lock.
+func resetChangeLog() {
+	revision++
+	changeLog = nil
+	changeLogStart = revision
+}
+
+// GetChanges returns the changes made after the revision given by since,
+// oldest first, along with the current revision to pass as since next
+// time. When the log no longer covers since it answers 410 Gone, and the
+// client has to fetch the full list instead.
+func GetChanges(c *gin.Context) {
+	var since uint64
+	if param := c.Query("since"); param != "" {
+		var err error
+		if since, err = strconv.ParseUint(param, 10, 64); err != nil {
+			respondJSON(c, http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid since %q: must be a revision number", param)})
+			return
+		}
+	}
+
+	todoMu.RLock()
+	defer todoMu.RUnlock()
+
+	if since < changeLogStart {
+		respondJSON(c, http.StatusGone, gin.H{
+			"error":           fmt.Sprintf("changes since revision %d are no longer available; fetch the full list", since),
+			"oldest_revision": changeLogStart,
+			"revision":        revision,
+		})
+		return
+	}
+
+	changes := []Change{}
+	for _, change := range changeLog {
+		if change.Revision > since {
+			changes = append(changes, change)
+		}
+	}
+	respondJSON(c, http.StatusOK, gin.H{"changes": changes, "revision": revision})
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+// changesResponse is the body of GET /todos/changes
+type changesResponse struct {
+	Changes  []Change `json:"changes"`
+	Revision uint64   `json:"revision"`
+}
+
+func TestChangeFeed(t *testing.T) {
+	r := newTestRouter(t, nil, nil)
+
+	var start changesResponse
+	decodeBody(t, doRequest(r, http.MethodGet, "/api/v1/todos/changes", ""), &start)
+	if len(start.Changes) != 0 {
+		t.Fatalf("fresh store has %d changes", len(start.Changes))
+	}
+
+	doRequest(r, http.MethodPost, "/api/v1/todos", `{"title":"First"}`)
+	doRequest(r, http.MethodPut, "/api/v1/todos/1", `{"title":"Renamed"}`)
+	doRequest(r, http.MethodDelete, "/api/v1/todos/1", "")
+
+	var feed changesResponse
+	since := strconv.FormatUint(start.Revision, 10)
+	decodeBody(t, doRequest(r, http.MethodGet, "/api/v1/todos/changes?since="+since, ""), &feed)
+	want := []string{eventTodoCreated, eventTodoUpdated, eventTodoDeleted}
+	if len(feed.Changes) != len(want) {
+		t.Fatalf("got %d changes, want %d: %+v", len(feed.Changes), len(want), feed.Changes)
+	}
+	for i, change := range feed.Changes {
+		if change.Type != want[i] {
+			t.Errorf("change %d has type %q, want %q", i, change.Type, want[i])
+		}
+		if i > 0 && change.Revision <= feed.Changes[i-1].Revision {
+			t.Errorf("change %d has revision %d after %d", i, change.Revision, feed.Changes[i-1].Revision)
+		}
+	}
+	if feed.Changes[1].Todo.Title != "Renamed" {
+		t.Errorf("update carried title %q", feed.Changes[1].Todo.Title)
+	}
+
+	var caughtUp changesResponse
+	since = strconv.FormatUint(feed.Revision, 10)
+	decodeBody(t, doRequest(r, http.MethodGet, "/api/v1/todos/changes?since="+since, ""), &caughtUp)
+	if len(caughtUp.Changes) != 0 || caughtUp.Revision != feed.Revision {
+		t.Errorf("caught-up feed: %+v", caughtUp)
+	}
+}
+
+func TestChangeFeedGoneWhenTrimmed(t *testing.T) {
+	r := newTestRouter(t, map[string]string{"CHANGE_LOG_SIZE": "1"}, nil)
+
+	var start changesResponse
+	decodeBody(t, doRequest(r, http.MethodGet, "/api/v1/todos/changes", ""), &start)
+	doRequest(r, http.MethodPost, "/api/v1/todos", `{"title":"First"}`)
+	doRequest(r, http.MethodPost, "/api/v1/todos", `{"title":"Second"}`)
+
+	since := strconv.FormatUint(start.Revision, 10)
+	if w := doRequest(r, http.MethodGet, "/api/v1/todos/changes?since="+since, ""); w.Code != http.StatusGone {
+		t.Errorf("trimmed feed: status %d, want 410", w.Code)
+	}
+	if w := doRequest(r, http.MethodGet, "/api/v1/todos/changes?since=x", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid since: status %d, want 400", w.Code)
+	}
+}
+
+func TestRevisionRisesByOnePerChange(t *testing.T) {
+	r := newTestRouter(t, nil, []Todo{{ID: 1, Title: "First"}, {ID: 2, Title: "Second"}})
+
+	current := func() uint64 {
+		w := doRequest(r, http.MethodHead, "/api/v1/todos", "")
+		rev, err := strconv.ParseUint(w.Header().Get("X-Revision"), 10, 64)
+		if err != nil {
+			t.Fatalf("X-Revision %q: %v", w.Header().Get("X-Revision"), err)
+		}
+		return rev
+	}
+
+	writes := []struct {
+		method, target, body string
+		changes              uint64
+	}{
+		{http.MethodPost, "/api/v1/todos", `{"title":"Third"}`, 1},
+		{http.MethodPut, "/api/v1/todos/1", `{"title":"Renamed"}`, 1},
+		{http.MethodPost, "/api/v1/todos/toggle", `{"ids":[1,2]}`, 2},
+		{http.MethodDelete, "/api/v1/todos/3", "", 1},
+	}
+	start := current()
+	for _, write := range writes {
+		before := current()
+		if w := doRequest(r, write.method, write.target, write.body); w.Code >= 300 {
+			t.Fatalf("%s %s: status %d, body %s", write.method, write.target, w.Code, w.Body)
+		}
+		if after := current(); after != before+write.changes {
+			t.Errorf("%s %s moved the revision from %d to %d, want +%d", write.method, write.target, before, after, write.changes)
+		}
+	}
+
+	// Every change in the feed has its own revision, with no gaps
+	var feed changesResponse
+	decodeBody(t, doRequest(r, http.MethodGet, "/api/v1/todos/changes?since="+strconv.FormatUint(start, 10), ""), &feed)
+	if len(feed.Changes) != 5 {
+		t.Fatalf("got %d changes, want 5", len(feed.Changes))
+	}
+	for i, change := range feed.Changes {
+		if change.Revision != start+uint64(i)+1 {
+			t.Errorf("change %d has revision %d, want %d", i, change.Revision, start+uint64(i)+1)
+		}
+	}
+	if last := feed.Changes[len(feed.Changes)-1].Revision; last != feed.Revision {
+		t.Errorf("last change has revision %d, feed revision %d", last, feed.Revision)
+	}
+}
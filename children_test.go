@@ -0,0 +1,97 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestCreateChildTodo(t *testing.T) {
+	r := newTestRouter(t, nil, []Todo{{ID: 1, Title: "Parent"}})
+
+	w := doRequest(r, http.MethodPost, "/api/v1/todos", `{"title":"Child","parent_id":1}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status %d, body %s", w.Code, w.Body)
+	}
+	var child Todo
+	decodeBody(t, w, &child)
+	if child.ParentID == nil || *child.ParentID != 1 {
+		t.Errorf("child has parent %v, want 1", child.ParentID)
+	}
+
+	w = doRequest(r, http.MethodPost, "/api/v1/todos", `{"title":"Orphan","parent_id":99}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("missing parent: status %d, body %s", w.Code, w.Body)
+	}
+}
+
+func TestParentCycleRejected(t *testing.T) {
+	parent := 1
+	child := 2
+	r := newTestRouter(t, nil, []Todo{
+		{ID: 1, Title: "Root"},
+		{ID: 2, Title: "Child", ParentID: &parent},
+		{ID: 3, Title: "Grandchild", ParentID: &child},
+	})
+
+	for _, body := range []string{
+		`{"title":"Root","parent_id":3}`,
+		`{"title":"Root","parent_id":1}`,
+	} {
+		w := doRequest(r, http.MethodPut, "/api/v1/todos/1", body)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("PUT %s: status %d, body %s", body, w.Code, w.Body)
+		}
+	}
+	if stored := storedTodos(); stored[0].ParentID != nil {
+		t.Errorf("root got parent %d", *stored[0].ParentID)
+	}
+}
+
+func TestGetTodoChildren(t *testing.T) {
+	parent := 1
+	child := 2
+	r := newTestRouter(t, nil, []Todo{
+		{ID: 1, Title: "Root"},
+		{ID: 2, Title: "Child", ParentID: &parent},
+		{ID: 3, Title: "Grandchild", ParentID: &child},
+		{ID: 4, Title: "Second child", ParentID: &parent},
+	})
+
+	w := doRequest(r, http.MethodGet, "/api/v1/todos/1/children", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, body %s", w.Code, w.Body)
+	}
+	var page struct {
+		Todos []Todo `json:"todos"`
+	}
+	decodeBody(t, w, &page)
+	var ids []int
+	for _, todo := range page.Todos {
+		ids = append(ids, todo.ID)
+	}
+	if len(ids) != 2 || ids[0] != 2 || ids[1] != 4 {
+		t.Errorf("children %v, want [2 4]", ids)
+	}
+
+	if w := doRequest(r, http.MethodGet, "/api/v1/todos/99/children", ""); w.Code != http.StatusNotFound {
+		t.Errorf("missing todo: status %d", w.Code)
+	}
+}
+
+func TestDeleteParentNeedsCascade(t *testing.T) {
+	parent := 1
+	r := newTestRouter(t, nil, []Todo{
+		{ID: 1, Title: "Root"},
+		{ID: 2, Title: "Child", ParentID: &parent},
+	})
+
+	if w := doRequest(r, http.MethodDelete, "/api/v1/todos/1", ""); w.Code != http.StatusConflict {
+		t.Fatalf("delete without cascade: status %d, body %s", w.Code, w.Body)
+	}
+	if w := doRequest(r, http.MethodDelete, "/api/v1/todos/1?cascade=true", ""); w.Code >= 300 {
+		t.Fatalf("delete with cascade: status %d, body %s", w.Code, w.Body)
+	}
+	if stored := storedTodos(); len(stored) != 0 {
+		t.Errorf("%d todos left after cascade", len(stored))
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CloneRequest is the body accepted when cloning todos. A nil
+// SourceProject clones every todo; a nil TargetProject keeps each clone in
+// its original project.
+type CloneRequest struct {
+	SourceProject *string `json:"source_project" binding:"omitempty,max=100"`
+	TargetProject *string `json:"target_project" binding:"omitempty,max=100"`
+}
+
+// CloneTodos copies the todos in the source project into the target
+// project as new, incomplete todos. Parent links between cloned todos are
+// pointed at the clones.
+func CloneTodos(c *gin.Context) {
+	var req CloneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondJSON(c, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	todoMu.Lock()
+	defer todoMu.Unlock()
+
+	now := time.Now()
+	cloneIDs := map[int]int{}
+	clones := []Todo{}
+	for _, todo := range todos {
+		if req.SourceProject != nil && todo.Project != *req.SourceProject {
+			continue
+		}
+
+		clone := todo
+		clone.ID = nextID + len(clones)
+		clone.Completed = false
+		clone.CompletedAt = nil
+		clone.Progress = 0
+		clone.Tags = slices.Clone(todo.Tags)
+		clone.Comments = nil
+		clone.CreatedAt = now
+		clone.UpdatedAt = now
+		if todo.DueDate != nil {
+			due := *todo.DueDate
+			clone.DueDate = &due
+		}
+		if req.TargetProject != nil {
+			clone.Project = *req.TargetProject
+		}
+		cloneIDs[todo.ID] = clone.ID
+		clones = append(clones, clone)
+	}
+
+	for i, clone := range clones {
+		if clone.ParentID == nil {
+			continue
+		}
+		parentID := *clone.ParentID
+		if cloned, ok := cloneIDs[parentID]; ok {
+			parentID = cloned
+		}
+		clones[i].ParentID = &parentID
+	}
+
+	if !idsAvailable(len(clones)) {
+		respondJSON(c, http.StatusInsufficientStorage, gin.H{"error": errIDsExhausted})
+		return
+	}
+
+	if isDryRun(c) {
+		respondJSON(c, http.StatusOK, gin.H{"dry_run": true, "cloned": len(clones)})
+		return
+	}
+
+	nextID += len(clones)
+	todos = append(todos, clones...)
+	if len(clones) > 0 {
+		invalidateListCache()
+	}
+	for _, clone := range clones {
+		publishChange(eventTodoCreated, clone)
+	}
+
+	respondJSON(c, http.StatusCreated, gin.H{"cloned": len(clones)})
+}
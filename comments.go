@@ -0,0 +1,85 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Comment is a timestamped note appended to a todo. Comments cannot be
+// edited or removed once added.
+type Comment struct {
+	Text      string    `json:"text" xml:"text"`
+	CreatedAt time.Time `json:"created_at" xml:"created_at"`
+}
+
+// CommentRequest is the body accepted when adding a comment
+type CommentRequest struct {
+	Text string `json:"text" binding:"required,max=2000"`
+}
+
+// AddTodoComment appends a comment to a todo
+func AddTodoComment(c *gin.Context) {
+	id, ok := parseTodoID(c)
+	if !ok {
+		return
+	}
+
+	var req CommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondJSON(c, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	todoMu.Lock()
+	defer todoMu.Unlock()
+
+	i := findTodoIndex(id)
+	if i == -1 {
+		respondJSON(c, http.StatusNotFound, gin.H{"error": "Todo not found"})
+		return
+	}
+
+	// Comments are not among the fields editsLocked compares, but adding
+	// one still edits the todo
+	if lockCompleted && todos[i].Completed {
+		respondJSON(c, http.StatusConflict, gin.H{"error": completedLockedMessage})
+		return
+	}
+
+	comment := Comment{Text: req.Text, CreatedAt: time.Now()}
+	if isDryRun(c) {
+		respondJSON(c, http.StatusOK, gin.H{"dry_run": true, "comment": comment})
+		return
+	}
+	todos[i].Comments = append(todos[i].Comments, comment)
+	todos[i].UpdatedAt = comment.CreatedAt
+	invalidateListCache()
+	publishChange(eventTodoUpdated, todos[i])
+
+	respondJSON(c, http.StatusCreated, comment)
+}
+
+// GetTodoComments returns a todo's comments, oldest first
+func GetTodoComments(c *gin.Context) {
+	id, ok := parseTodoID(c)
+	if !ok {
+		return
+	}
+
+	todoMu.RLock()
+	defer todoMu.RUnlock()
+
+	i := findTodoIndex(id)
+	if i == -1 {
+		respondJSON(c, http.StatusNotFound, gin.H{"error": "Todo not found"})
+		return
+	}
+
+	comments := todos[i].Comments
+	if comments == nil {
+		comments = []Comment{}
+	}
+	respondJSON(c, http.StatusOK, gin.H{"comments": comments})
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// compactTodos reallocates the todo slice at its current length, releasing
+// the spare capacity left behind by deletes, and returns how many slots
+// were reclaimed
+func compactTodos() int {
+	todoMu.Lock()
+	defer todoMu.Unlock()
+
+	reclaimed := cap(todos) - len(todos)
+	if reclaimed == 0 {
+		return 0
+	}
+	compacted := make([]Todo, len(todos))
+	copy(compacted, todos)
+	todos = compacted
+	return reclaimed
+}
+
+// runCompaction compacts the todo slice every interval until ctx is
+// cancelled
+func runCompaction(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if reclaimed := compactTodos(); reclaimed > 0 {
+				log.Printf("Compaction: reclaimed %d unused todo slots", reclaimed)
+			}
+		}
+	}
+}
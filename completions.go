@@ -0,0 +1,130 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxRangeDays bounds the range of a per-day count request
+const maxRangeDays = 366
+
+// dateLayout is the format of the from and to query parameters and of the
+// reported days
+const dateLayout = "2006-01-02"
+
+// DayCount is the number of todos counted on one day
+type DayCount struct {
+	Date  string `json:"date" xml:"date"`
+	Count int    `json:"count" xml:"count"`
+}
+
+// countPerDay counts the todos in items whose time returned by at falls on
+// each day from from to to inclusive, both midnights in the location days
+// are counted in. Todos for which at returns nil are not counted. Every day
+// in the range is reported, including those without any todos.
+func countPerDay(items []Todo, from, to time.Time, at func(Todo) *time.Time) []DayCount {
+	counts := map[string]int{}
+	end := to.AddDate(0, 0, 1)
+	for _, todo := range items {
+		t := at(todo)
+		if t == nil {
+			continue
+		}
+		local := t.In(from.Location())
+		if local.Before(from) || !local.Before(end) {
+			continue
+		}
+		counts[local.Format(dateLayout)]++
+	}
+
+	days := []DayCount{}
+	for day := from; day.Before(end); day = day.AddDate(0, 0, 1) {
+		date := day.Format(dateLayout)
+		days = append(days, DayCount{Date: date, Count: counts[date]})
+	}
+	return days
+}
+
+// completedAt returns when todo was completed, or nil while it is not
+func completedAt(todo Todo) *time.Time {
+	if !todo.Completed {
+		return nil
+	}
+	return todo.CompletedAt
+}
+
+// createdAt returns when todo was created
+func createdAt(todo Todo) *time.Time {
+	return &todo.CreatedAt
+}
+
+// dayRange parses the from and to query parameters as dates in
+// loc. Both are required, from must not come after to, and the range may
+// span at most maxRangeDays days.
+func dayRange(c *gin.Context, loc *time.Location) (time.Time, time.Time, error) {
+	fromParam, toParam := c.Query("from"), c.Query("to")
+	if fromParam == "" || toParam == "" {
+		return time.Time{}, time.Time{}, errors.New("from and to are required, as dates such as 2024-01-31")
+	}
+	from, err := time.ParseInLocation(dateLayout, fromParam, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid from %q: must be a date such as 2024-01-31", fromParam)
+	}
+	to, err := time.ParseInLocation(dateLayout, toParam, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid to %q: must be a date such as 2024-01-31", toParam)
+	}
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, errors.New("from must not be after to")
+	}
+	if from.AddDate(0, 0, maxRangeDays).Before(to.AddDate(0, 0, 1)) {
+		return time.Time{}, time.Time{}, fmt.Errorf("range must span at most %d days", maxRangeDays)
+	}
+	return from, to, nil
+}
+
+// GetCompletions reports how many todos were completed on each day of the
+// requested range, with days taken in the time zone named by tz
+func GetCompletions(c *gin.Context) {
+	now, err := requestNow(c)
+	if err != nil {
+		respondJSON(c, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	from, to, err := dayRange(c, now.Location())
+	if err != nil {
+		respondJSON(c, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	todoMu.RLock()
+	days := countPerDay(todos, from, to, completedAt)
+	todoMu.RUnlock()
+
+	respondJSON(c, http.StatusOK, gin.H{"completions": days})
+}
+
+// GetActivity reports how many todos were created on each day of the
+// requested range, with days taken in the time zone named by tz
+func GetActivity(c *gin.Context) {
+	now, err := requestNow(c)
+	if err != nil {
+		respondJSON(c, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	from, to, err := dayRange(c, now.Location())
+	if err != nil {
+		respondJSON(c, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	todoMu.RLock()
+	days := countPerDay(todos, from, to, createdAt)
+	todoMu.RUnlock()
+
+	respondJSON(c, http.StatusOK, gin.H{"activity": days})
+}
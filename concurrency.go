@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Modes for requests arriving while every concurrency slot is taken
+const (
+	concurrencyReject = "reject"
+	concurrencyQueue  = "queue"
+)
+
+// parseConcurrencyMode validates a CONCURRENCY_MODE value
+func parseConcurrencyMode(value string) (string, error) {
+	switch value {
+	case concurrencyReject, concurrencyQueue:
+		return value, nil
+	default:
+		return "", fmt.Errorf("invalid CONCURRENCY_MODE %q: must be reject or queue", value)
+	}
+}
+
+// limitConcurrency lets at most limit requests run the remaining handlers
+// at once. In queue mode a request over the limit waits up to wait for a
+// slot; otherwise, or once the wait runs out, it gets 503 Service
+// Unavailable.
+func limitConcurrency(limit int, mode string, wait time.Duration) gin.HandlerFunc {
+	slots := make(chan struct{}, limit)
+	return func(c *gin.Context) {
+		select {
+		case slots <- struct{}{}:
+		default:
+			if mode != concurrencyQueue || !waitForSlot(c, slots, wait) {
+				c.Header("Retry-After", "1")
+				respondJSON(c, http.StatusServiceUnavailable, gin.H{"error": "Server is busy, try again later"})
+				c.Abort()
+				return
+			}
+		}
+		defer func() { <-slots }()
+
+		c.Next()
+	}
+}
+
+// waitForSlot blocks until a slot frees up, wait elapses or the client goes
+// away, reporting whether a slot was taken
+func waitForSlot(c *gin.Context, slots chan struct{}, wait time.Duration) bool {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case slots <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-c.Request.Context().Done():
+		return false
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newBlockingRouter serves /block behind handler, signalling entered when
+// a request reaches the handler and holding it until release is closed
+func newBlockingRouter(handler gin.HandlerFunc, entered chan<- struct{}, release <-chan struct{}) *gin.Engine {
+	r := gin.New()
+	r.Use(handler)
+	block := func(c *gin.Context) {
+		entered <- struct{}{}
+		<-release
+		c.Status(http.StatusNoContent)
+	}
+	r.GET("/block", block)
+	r.POST("/block", block)
+	return r
+}
+
+// serveAsync serves a request in the background, delivering the response
+// on the returned channel
+func serveAsync(handler http.Handler, method, target string) <-chan *httptest.ResponseRecorder {
+	done := make(chan *httptest.ResponseRecorder, 1)
+	go func() { done <- doRequest(handler, method, target, "") }()
+	return done
+}
+
+func TestLimitConcurrencyRejects(t *testing.T) {
+	entered := make(chan struct{}, 2)
+	release := make(chan struct{})
+	r := newBlockingRouter(limitConcurrency(1, concurrencyReject, 0), entered, release)
+
+	first := serveAsync(r, http.MethodGet, "/block")
+	<-entered
+
+	w := doRequest(r, http.MethodGet, "/block", "")
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "1" {
+		t.Errorf("request over the limit: status %d, Retry-After %q", w.Code, w.Header().Get("Retry-After"))
+	}
+
+	close(release)
+	if w := <-first; w.Code != http.StatusNoContent {
+		t.Errorf("first request: status %d", w.Code)
+	}
+}
+
+func TestLimitConcurrencyQueues(t *testing.T) {
+	entered := make(chan struct{}, 2)
+	release := make(chan struct{})
+	r := newBlockingRouter(limitConcurrency(1, concurrencyQueue, time.Minute), entered, release)
+
+	first := serveAsync(r, http.MethodGet, "/block")
+	<-entered
+	second := serveAsync(r, http.MethodGet, "/block")
+
+	select {
+	case <-entered:
+		t.Fatal("second request ran while the slot was taken")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	for i, done := range []<-chan *httptest.ResponseRecorder{first, second} {
+		if w := <-done; w.Code != http.StatusNoContent {
+			t.Errorf("request %d: status %d", i+1, w.Code)
+		}
+	}
+}
+
+func TestLimitConcurrencyQueueWaitRunsOut(t *testing.T) {
+	entered := make(chan struct{}, 2)
+	release := make(chan struct{})
+	r := newBlockingRouter(limitConcurrency(1, concurrencyQueue, 10*time.Millisecond), entered, release)
+
+	first := serveAsync(r, http.MethodGet, "/block")
+	<-entered
+
+	if w := doRequest(r, http.MethodGet, "/block", ""); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("request after the wait: status %d, want 503", w.Code)
+	}
+	close(release)
+	<-first
+}
+
+func TestLimitConcurrencyShedsExcess(t *testing.T) {
+	const limit, total = 2, 6
+	entered := make(chan struct{}, total)
+	release := make(chan struct{})
+	r := newBlockingRouter(limitConcurrency(limit, concurrencyReject, 0), entered, release)
+
+	results := make(chan *httptest.ResponseRecorder, total)
+	for i := 0; i < total; i++ {
+		go func() { results <- doRequest(r, http.MethodGet, "/block", "") }()
+	}
+
+	// While the admitted requests hold every slot, the rest are shed
+	for i := 0; i < total-limit; i++ {
+		select {
+		case w := <-results:
+			if w.Code != http.StatusServiceUnavailable {
+				t.Errorf("request over the limit: status %d", w.Code)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("only %d requests were shed", i)
+		}
+	}
+	for i := 0; i < limit; i++ {
+		<-entered
+	}
+	if len(entered) != 0 {
+		t.Errorf("%d requests ran, want %d", limit+len(entered), limit)
+	}
+
+	close(release)
+	for i := 0; i < limit; i++ {
+		if w := <-results; w.Code != http.StatusNoContent {
+			t.Errorf("admitted request: status %d", w.Code)
+		}
+	}
+}
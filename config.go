@@ -0,0 +1,418 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Config holds the server settings loaded from the environment. Each field
+// notes the variable it comes from.
+type Config struct {
+	Port string // PORT
+
+	DefaultPageSize int // PAGE_SIZE
+	MaxPageSize     int // MAX_PAGE_SIZE
+	TotalCountCap   int // TOTAL_COUNT_CAP
+	DefaultSort     *todoSort
+	ListCacheTTL    time.Duration // LIST_CACHE_TTL
+	PrettyJSON      bool          // PRETTY_JSON
+	Envelope        bool          // RESPONSE_ENVELOPE
+	ListKey         string        // LIST_RESPONSE_KEY
+	ListShape       string        // LIST_SHAPE
+
+	AutoCompleteOnProgress  bool // AUTO_COMPLETE_ON_PROGRESS
+	UpsertOnPut             bool // UPSERT_ON_PUT
+	StrictJSON              bool // STRICT_JSON
+	RequireDescription      bool // REQUIRE_DESCRIPTION
+	RequireSubtasksComplete bool // REQUIRE_SUBTASKS_COMPLETE
+	LockCompleted           bool // LOCK_COMPLETED
+	UnprocessableValidation bool // UNPROCESSABLE_VALIDATION
+	MaxTags                 int  // MAX_TAGS
+	MaxTagLength            int  // MAX_TAG_LENGTH
+
+	MaxTitleLength       int    // MAX_TITLE_LENGTH
+	MaxDescriptionLength int    // MAX_DESCRIPTION_LENGTH
+	LengthMode           string // LENGTH_MODE
+
+	Defaults TodoDefaults
+
+	AdminEnabled bool   // ENABLE_ADMIN
+	AdminAPIKey  string // ADMIN_API_KEY
+
+	WebhookURLs       []string      // WEBHOOK_URLS
+	WebhookTimeout    time.Duration // WEBHOOK_TIMEOUT
+	WebhookMaxRetries int           // WEBHOOK_MAX_RETRIES
+
+	CompletedRetention time.Duration // COMPLETED_RETENTION
+	RetentionInterval  time.Duration // RETENTION_INTERVAL
+	CompactionInterval time.Duration // COMPACTION_INTERVAL
+	ChangeLogSize      int           // CHANGE_LOG_SIZE
+
+	CORSEnabled        bool          // CORS_ENABLED
+	CORSAllowedOrigins []string      // CORS_ALLOWED_ORIGINS
+	CORSMaxAge         time.Duration // CORS_MAX_AGE
+	CORSExposeHeaders  []string      // CORS_EXPOSE_HEADERS
+
+	MaxConcurrentRequests int           // MAX_CONCURRENT_REQUESTS
+	ConcurrencyMode       string        // CONCURRENCY_MODE
+	ConcurrencyQueueWait  time.Duration // CONCURRENCY_QUEUE_WAIT
+	WriteQueueSize        int           // WRITE_QUEUE_SIZE
+
+	RedirectTrailingSlash bool // REDIRECT_TRAILING_SLASH
+	RemoveExtraSlash      bool // REMOVE_EXTRA_SLASH
+
+	RequestIDHeader      string        // REQUEST_ID_HEADER
+	MaxJSONDepth         int           // MAX_JSON_DEPTH
+	MaxBodyBytes         int           // MAX_BODY_BYTES
+	SlowRequestThreshold time.Duration // SLOW_REQUEST_THRESHOLD_MS
+	RequestTimeout       time.Duration // REQUEST_TIMEOUT
+	DedupWindow          time.Duration // DEDUP_WINDOW
+	GzipEnabled          bool          // GZIP_MIN_SIZE is set
+	GzipMinSize          int           // GZIP_MIN_SIZE
+	ShutdownTimeout      time.Duration // SHUTDOWN_TIMEOUT
+}
+
+// TodoDefaults are the values new todos get for fields they leave out
+type TodoDefaults struct {
+	Priority string   // DEFAULT_PRIORITY
+	Project  string   // DEFAULT_PROJECT
+	Tags     []string // DEFAULT_TAGS
+}
+
+// applyTo fills in the fields todo leaves empty and adds the default tags
+// it lacks. Tags are not normalized.
+func (d TodoDefaults) applyTo(todo *Todo) {
+	if todo.Priority == "" {
+		todo.Priority = d.Priority
+	}
+	if todo.Project == "" {
+		todo.Project = d.Project
+	}
+	if len(d.Tags) > 0 {
+		todo.Tags = append(slices.Clone(todo.Tags), d.Tags...)
+	}
+}
+
+// defaultConfig returns the settings used when no variables are set
+func defaultConfig() Config {
+	return Config{
+		Port:                  "8080",
+		DefaultPageSize:       10,
+		MaxPageSize:           100,
+		MaxTags:               20,
+		MaxTagLength:          50,
+		WebhookTimeout:        5 * time.Second,
+		WebhookMaxRetries:     3,
+		RetentionInterval:     time.Hour,
+		CORSEnabled:           true,
+		CORSAllowedOrigins:    []string{"*"},
+		CORSMaxAge:            10 * time.Minute,
+		CORSExposeHeaders:     []string{"X-Total-Count", "X-Deduplicated", "ETag", "Location", "X-Revision"},
+		ConcurrencyMode:       concurrencyReject,
+		ConcurrencyQueueWait:  time.Second,
+		ShutdownTimeout:       10 * time.Second,
+		RedirectTrailingSlash: true,
+		RequestIDHeader:       "X-Request-ID",
+		ListKey:               "todos",
+		ListShape:             listShapeObject,
+		LengthMode:            lengthRunes,
+		MaxJSONDepth:          32,
+		MaxBodyBytes:          1 << 20,
+		ChangeLogSize:         1000,
+	}
+}
+
+// splitList splits a comma-separated value, dropping blank entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// validHeaderName reports whether name is a valid HTTP header field name
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("!#$%&'*+-.^_`|~", r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// envDuration parses the named variable into dst when it is set, requiring
+// it to be positive, or non-negative when allowZero is set
+func envDuration(lookup func(string) (string, bool), name string, allowZero bool, example string, dst *time.Duration) error {
+	value, ok := lookup(name)
+	if !ok || value == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 || (d == 0 && !allowZero) {
+		if allowZero {
+			return fmt.Errorf("invalid %s %q: must be a non-negative duration such as %s", name, value, example)
+		}
+		return fmt.Errorf("invalid %s %q: must be a positive duration such as %s", name, value, example)
+	}
+	*dst = d
+	return nil
+}
+
+// envInt parses the named variable into dst when it is set, requiring it
+// to be at least min
+func envInt(lookup func(string) (string, bool), name string, min int, dst *int) error {
+	value, ok := lookup(name)
+	if !ok || value == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < min {
+		switch min {
+		case 0:
+			return fmt.Errorf("invalid %s %q: must be a non-negative integer", name, value)
+		case 1:
+			return fmt.Errorf("invalid %s %q: must be a positive integer", name, value)
+		default:
+			return fmt.Errorf("invalid %s %q: must be an integer of at least %d", name, value, min)
+		}
+	}
+	*dst = n
+	return nil
+}
+
+// envBool parses the named variable into dst when it is set
+func envBool(lookup func(string) (string, bool), name string, dst *bool) error {
+	value, ok := lookup(name)
+	if !ok || value == "" {
+		return nil
+	}
+	if value != "true" && value != "false" {
+		return fmt.Errorf("invalid %s %q: must be true or false", name, value)
+	}
+	*dst = value == "true"
+	return nil
+}
+
+// loadConfig reads the configuration through lookup, normally
+// os.LookupEnv, and validates it
+func loadConfig(lookup func(string) (string, bool)) (Config, error) {
+	cfg := defaultConfig()
+	cfg.AdminAPIKey, _ = lookup("ADMIN_API_KEY")
+
+	if port, ok := lookup("PORT"); ok && port != "" {
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return Config{}, fmt.Errorf("invalid PORT %q: must be a number between 1 and 65535", port)
+		}
+		cfg.Port = port
+	}
+
+	// A page size below 1 is not fatal: it falls back to 1 with a warning
+	if value, ok := lookup("PAGE_SIZE"); ok && value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid PAGE_SIZE %q: must be an integer", value)
+		}
+		if n < 1 {
+			log.Printf("WARN PAGE_SIZE %d is below 1, using 1", n)
+			n = 1
+		}
+		cfg.DefaultPageSize = n
+	}
+
+	var threshold int
+	for _, err := range []error{
+		envInt(lookup, "MAX_PAGE_SIZE", 1, &cfg.MaxPageSize),
+		envInt(lookup, "TOTAL_COUNT_CAP", 0, &cfg.TotalCountCap),
+		envInt(lookup, "MAX_TAGS", 0, &cfg.MaxTags),
+		envInt(lookup, "MAX_TAG_LENGTH", 1, &cfg.MaxTagLength),
+		envInt(lookup, "MAX_TITLE_LENGTH", 0, &cfg.MaxTitleLength),
+		envInt(lookup, "MAX_DESCRIPTION_LENGTH", 0, &cfg.MaxDescriptionLength),
+		envInt(lookup, "WEBHOOK_MAX_RETRIES", 0, &cfg.WebhookMaxRetries),
+		envInt(lookup, "SLOW_REQUEST_THRESHOLD_MS", 1, &threshold),
+		envInt(lookup, "GZIP_MIN_SIZE", 0, &cfg.GzipMinSize),
+		envInt(lookup, "MAX_CONCURRENT_REQUESTS", 0, &cfg.MaxConcurrentRequests),
+		envInt(lookup, "WRITE_QUEUE_SIZE", 0, &cfg.WriteQueueSize),
+		envInt(lookup, "MAX_JSON_DEPTH", 1, &cfg.MaxJSONDepth),
+		envInt(lookup, "MAX_BODY_BYTES", 1, &cfg.MaxBodyBytes),
+		envInt(lookup, "CHANGE_LOG_SIZE", 1, &cfg.ChangeLogSize),
+		envDuration(lookup, "LIST_CACHE_TTL", false, "5s", &cfg.ListCacheTTL),
+		envDuration(lookup, "WEBHOOK_TIMEOUT", false, "5s", &cfg.WebhookTimeout),
+		envDuration(lookup, "COMPLETED_RETENTION", false, "720h", &cfg.CompletedRetention),
+		envDuration(lookup, "RETENTION_INTERVAL", false, "1h", &cfg.RetentionInterval),
+		envDuration(lookup, "COMPACTION_INTERVAL", false, "10m", &cfg.CompactionInterval),
+		envDuration(lookup, "CORS_MAX_AGE", true, "10m", &cfg.CORSMaxAge),
+		envDuration(lookup, "REQUEST_TIMEOUT", false, "30s", &cfg.RequestTimeout),
+		envDuration(lookup, "DEDUP_WINDOW", false, "500ms", &cfg.DedupWindow),
+		envDuration(lookup, "SHUTDOWN_TIMEOUT", false, "10s", &cfg.ShutdownTimeout),
+		envDuration(lookup, "CONCURRENCY_QUEUE_WAIT", false, "1s", &cfg.ConcurrencyQueueWait),
+		envBool(lookup, "AUTO_COMPLETE_ON_PROGRESS", &cfg.AutoCompleteOnProgress),
+		envBool(lookup, "PRETTY_JSON", &cfg.PrettyJSON),
+		envBool(lookup, "RESPONSE_ENVELOPE", &cfg.Envelope),
+		envBool(lookup, "UPSERT_ON_PUT", &cfg.UpsertOnPut),
+		envBool(lookup, "STRICT_JSON", &cfg.StrictJSON),
+		envBool(lookup, "REQUIRE_DESCRIPTION", &cfg.RequireDescription),
+		envBool(lookup, "REQUIRE_SUBTASKS_COMPLETE", &cfg.RequireSubtasksComplete),
+		envBool(lookup, "LOCK_COMPLETED", &cfg.LockCompleted),
+		envBool(lookup, "UNPROCESSABLE_VALIDATION", &cfg.UnprocessableValidation),
+		envBool(lookup, "ENABLE_ADMIN", &cfg.AdminEnabled),
+		envBool(lookup, "REDIRECT_TRAILING_SLASH", &cfg.RedirectTrailingSlash),
+		envBool(lookup, "REMOVE_EXTRA_SLASH", &cfg.RemoveExtraSlash),
+		envBool(lookup, "CORS_ENABLED", &cfg.CORSEnabled),
+	} {
+		if err != nil {
+			return Config{}, err
+		}
+	}
+	cfg.SlowRequestThreshold = time.Duration(threshold) * time.Millisecond
+	if value, ok := lookup("GZIP_MIN_SIZE"); ok && value != "" {
+		cfg.GzipEnabled = true
+	}
+
+	if cfg.DefaultPageSize > cfg.MaxPageSize {
+		return Config{}, fmt.Errorf("invalid PAGE_SIZE %d: must not exceed MAX_PAGE_SIZE %d", cfg.DefaultPageSize, cfg.MaxPageSize)
+	}
+
+	if cfg.AdminEnabled && cfg.AdminAPIKey == "" {
+		return Config{}, fmt.Errorf("ENABLE_ADMIN requires ADMIN_API_KEY to be set")
+	}
+
+	if value, ok := lookup("CONCURRENCY_MODE"); ok && value != "" {
+		var err error
+		if cfg.ConcurrencyMode, err = parseConcurrencyMode(value); err != nil {
+			return Config{}, err
+		}
+	}
+
+	if value, ok := lookup("LIST_RESPONSE_KEY"); ok && value != "" {
+		if slices.Contains(listMetadataKeys, value) {
+			return Config{}, fmt.Errorf("invalid LIST_RESPONSE_KEY %q: clashes with a list metadata key", value)
+		}
+		cfg.ListKey = value
+	}
+
+	if value, ok := lookup("LIST_SHAPE"); ok && value != "" {
+		var err error
+		if cfg.ListShape, err = parseListShape(value); err != nil {
+			return Config{}, err
+		}
+	}
+
+	if value, ok := lookup("LENGTH_MODE"); ok && value != "" {
+		var err error
+		if cfg.LengthMode, err = parseLengthMode(value); err != nil {
+			return Config{}, err
+		}
+	}
+
+	if value, ok := lookup("DEFAULT_SORT"); ok && value != "" {
+		var err error
+		if cfg.DefaultSort, err = parseSort(value); err != nil {
+			return Config{}, fmt.Errorf("invalid DEFAULT_SORT: %v", err)
+		}
+	}
+
+	if value, ok := lookup("WEBHOOK_URLS"); ok && value != "" {
+		for _, target := range strings.Split(value, ",") {
+			target = strings.TrimSpace(target)
+			if !validHTTPURL(target) {
+				return Config{}, fmt.Errorf("invalid WEBHOOK_URLS entry %q: must be an absolute http or https URL", target)
+			}
+			if checkDestinationURL(target) != nil {
+				return Config{}, fmt.Errorf("invalid WEBHOOK_URLS entry %q: must not point to a loopback, private or link-local address", target)
+			}
+			cfg.WebhookURLs = append(cfg.WebhookURLs, target)
+		}
+	}
+
+	if value, ok := lookup("CORS_ALLOWED_ORIGINS"); ok && value != "" {
+		cfg.CORSAllowedOrigins = splitList(value)
+		if len(cfg.CORSAllowedOrigins) == 0 {
+			return Config{}, fmt.Errorf("invalid CORS_ALLOWED_ORIGINS %q: must list at least one origin or *", value)
+		}
+	}
+	if value, ok := lookup("DEFAULT_PRIORITY"); ok && value != "" {
+		if _, ok := priorityRanks[value]; !ok {
+			return Config{}, fmt.Errorf("invalid DEFAULT_PRIORITY %q: must be low, medium or high", value)
+		}
+		cfg.Defaults.Priority = value
+	}
+	if value, ok := lookup("DEFAULT_PROJECT"); ok && value != "" {
+		if utf8.RuneCountInString(value) > 100 {
+			return Config{}, fmt.Errorf("invalid DEFAULT_PROJECT %q: longer than 100 characters", value)
+		}
+		cfg.Defaults.Project = value
+	}
+	if value, ok := lookup("DEFAULT_TAGS"); ok {
+		cfg.Defaults.Tags = splitList(value)
+	}
+	for _, tag := range cfg.Defaults.Tags {
+		if utf8.RuneCountInString(tag) > cfg.MaxTagLength {
+			return Config{}, fmt.Errorf("invalid DEFAULT_TAGS entry %q: longer than MAX_TAG_LENGTH %d", tag, cfg.MaxTagLength)
+		}
+	}
+	if len(cfg.Defaults.Tags) > cfg.MaxTags {
+		return Config{}, fmt.Errorf("invalid DEFAULT_TAGS: more than MAX_TAGS %d tags", cfg.MaxTags)
+	}
+
+	if value, ok := lookup("REQUEST_ID_HEADER"); ok && value != "" {
+		if !validHeaderName(value) {
+			return Config{}, fmt.Errorf("invalid REQUEST_ID_HEADER %q: must be an HTTP header name such as X-Correlation-ID", value)
+		}
+		cfg.RequestIDHeader = value
+	}
+
+	if value, ok := lookup("CORS_EXPOSE_HEADERS"); ok {
+		cfg.CORSExposeHeaders = splitList(value)
+	}
+
+	return cfg, nil
+}
+
+// apply copies the settings read by handlers into their package variables
+func (cfg Config) apply() {
+	defaultPageSize = cfg.DefaultPageSize
+	maxPageSize = cfg.MaxPageSize
+	totalCountCap = cfg.TotalCountCap
+	defaultSort = cfg.DefaultSort
+	prettyJSONDefault = cfg.PrettyJSON
+	envelopeDefault = cfg.Envelope
+	listKey = cfg.ListKey
+	listShapeDefault = cfg.ListShape
+	autoCompleteOnProgress = cfg.AutoCompleteOnProgress
+	upsertOnPut = cfg.UpsertOnPut
+	strictJSON = cfg.StrictJSON
+	requireDescription = cfg.RequireDescription
+	requireSubtasksComplete = cfg.RequireSubtasksComplete
+	lockCompleted = cfg.LockCompleted
+	unprocessableValidation = cfg.UnprocessableValidation
+	maxTags = cfg.MaxTags
+	maxTagLength = cfg.MaxTagLength
+	maxTitleLength = cfg.MaxTitleLength
+	maxDescriptionLength = cfg.MaxDescriptionLength
+	lengthMode = cfg.LengthMode
+	todoDefaults = cfg.Defaults
+	adminAPIKey = cfg.AdminAPIKey
+	changeLogSize = cfg.ChangeLogSize
+
+	listResponseCache = nil
+	if cfg.ListCacheTTL > 0 {
+		listResponseCache = newListCache(cfg.ListCacheTTL)
+	}
+
+	webhooks.client.Timeout = cfg.WebhookTimeout
+	webhooks.maxRetries = cfg.WebhookMaxRetries
+	for _, target := range cfg.WebhookURLs {
+		webhooks.register(target)
+	}
+}
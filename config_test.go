@@ -0,0 +1,64 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+// lookupFrom returns a lookup function reading from env, like
+// os.LookupEnv does from the environment
+func lookupFrom(env map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+}
+
+func TestPageSizeBelowOneFallsBack(t *testing.T) {
+	for _, value := range []string{"0", "-5"} {
+		cfg, err := loadConfig(lookupFrom(map[string]string{"PAGE_SIZE": value}))
+		if err != nil {
+			t.Fatalf("PAGE_SIZE=%s: %v", value, err)
+		}
+		if cfg.DefaultPageSize != 1 {
+			t.Errorf("PAGE_SIZE=%s: DefaultPageSize = %d, want 1", value, cfg.DefaultPageSize)
+		}
+	}
+
+	r := newTestRouter(t, map[string]string{"PAGE_SIZE": "0"}, []Todo{{ID: 1, Title: "First"}, {ID: 2, Title: "Second"}})
+	var page struct {
+		Todos      []Todo `json:"todos"`
+		PerPage    int    `json:"per_page"`
+		TotalPages int    `json:"total_pages"`
+	}
+	decodeBody(t, doRequest(r, http.MethodGet, "/api/v1/todos", ""), &page)
+	if len(page.Todos) != 1 || page.PerPage != 1 || page.TotalPages != 2 {
+		t.Errorf("list with a zero page size: %+v", page)
+	}
+}
+
+func TestBoolSettings(t *testing.T) {
+	cfg, err := loadConfig(lookupFrom(map[string]string{
+		"STRICT_JSON":    "true",
+		"LOCK_COMPLETED": "true",
+		"CORS_ENABLED":   "false",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.StrictJSON || !cfg.LockCompleted || cfg.CORSEnabled {
+		t.Errorf("StrictJSON, LockCompleted, CORSEnabled = %t, %t, %t, want true, true, false",
+			cfg.StrictJSON, cfg.LockCompleted, cfg.CORSEnabled)
+	}
+
+	for _, env := range []map[string]string{
+		{"STRICT_JSON": "1"},
+		{"LOCK_COMPLETED": "yes"},
+		{"ENABLE_ADMIN": "TRUE", "ADMIN_API_KEY": "secret"},
+		{"REMOVE_EXTRA_SLASH": "off"},
+	} {
+		if _, err := loadConfig(lookupFrom(env)); err == nil {
+			t.Errorf("%v was accepted", env)
+		}
+	}
+}
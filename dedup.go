@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// dedupEntry is the shared outcome of one deduplicated request. done is
+// closed once the response has been recorded.
+type dedupEntry struct {
+	done   chan struct{}
+	status int
+	header http.Header
+	body   []byte
+}
+
+// requestDeduper collapses identical mutating requests that arrive while
+// the first one is in flight, or within window after it completed, into a
+// single execution whose response is replayed to every caller
+type requestDeduper struct {
+	window  time.Duration
+	mu      sync.Mutex
+	entries map[string]*dedupEntry
+}
+
+// recordingWriter tees the response body so it can be replayed
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+func newRequestDeduper(window time.Duration) *requestDeduper {
+	return &requestDeduper{window: window, entries: map[string]*dedupEntry{}}
+}
+
+// dedupKeyHeaders are the request headers that can change who may make a
+// request or how its response is rendered, so requests only collapse when
+// they agree on all of them
+var dedupKeyHeaders = []string{"X-Admin-Key", "Authorization", "Accept", "Accept-Encoding", "X-Envelope"}
+
+// dedupKey identifies a request by method, URI and a hash of its body and
+// dedupKeyHeaders. Credentials are only kept hashed.
+func dedupKey(r *http.Request, body []byte) string {
+	h := sha256.New()
+	for _, name := range dedupKeyHeaders {
+		fmt.Fprintf(h, "%s: %q\n", name, r.Header.Values(name))
+	}
+	h.Write(body)
+	return r.Method + " " + r.URL.RequestURI() + " " + hex.EncodeToString(h.Sum(nil))
+}
+
+// replayedHeader reports whether a response header recorded for the first
+// of a set of duplicate requests is replayed to the others. CORS headers
+// depend on each caller's Origin, and encoding headers are set again by
+// the replaying caller's own response writer.
+func replayedHeader(name string) bool {
+	switch name {
+	case "Content-Encoding", "Content-Length", "Vary":
+		return false
+	}
+	return !strings.HasPrefix(name, "Access-Control-")
+}
+
+// Middleware returns the deduplicating middleware. Safe requests pass
+// through untouched.
+func (d *requestDeduper) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.Abort()
+			respondJSON(c, http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		key := dedupKey(c.Request, body)
+
+		d.mu.Lock()
+		if entry, ok := d.entries[key]; ok {
+			d.mu.Unlock()
+			<-entry.done
+			for name, values := range entry.header {
+				c.Writer.Header()[name] = slices.Clone(values)
+			}
+			c.Header("X-Deduplicated", "true")
+			c.Writer.WriteHeader(entry.status)
+			c.Writer.Write(entry.body)
+			c.Abort()
+			return
+		}
+		entry := &dedupEntry{done: make(chan struct{})}
+		d.entries[key] = entry
+		d.mu.Unlock()
+
+		// Headers already set belong to this request, such as its request
+		// ID, so only those the handlers set are recorded
+		before := c.Writer.Header().Clone()
+		recorder := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		defer func() {
+			c.Writer = recorder.ResponseWriter
+			entry.status = recorder.Status()
+			entry.header = http.Header{}
+			for name, values := range recorder.Header() {
+				if replayedHeader(name) && !slices.Equal(before[name], values) {
+					entry.header[name] = slices.Clone(values)
+				}
+			}
+			entry.body = recorder.body.Bytes()
+			close(entry.done)
+
+			time.AfterFunc(d.window, func() {
+				d.mu.Lock()
+				defer d.mu.Unlock()
+				if d.entries[key] == entry {
+					delete(d.entries, key)
+				}
+			})
+		}()
+
+		c.Next()
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDedupKeyIncludesHeaders(t *testing.T) {
+	body := []byte(`{"title":"a"}`)
+	plain := httptest.NewRequest(http.MethodPost, "/api/v1/todos", nil)
+	if dedupKey(plain, body) != dedupKey(plain.Clone(plain.Context()), body) {
+		t.Fatal("identical requests have different keys")
+	}
+	if dedupKey(plain, body) == dedupKey(plain, []byte(`{"title":"b"}`)) {
+		t.Error("different bodies share a key")
+	}
+
+	for _, name := range dedupKeyHeaders {
+		other := plain.Clone(plain.Context())
+		other.Header.Set(name, "x")
+		if dedupKey(plain, body) == dedupKey(other, body) {
+			t.Errorf("requests differing in %s share a key", name)
+		}
+	}
+}
+
+func TestDedupReplaysRepeatedWrite(t *testing.T) {
+	r := newTestRouter(t, map[string]string{"DEDUP_WINDOW": "1m"}, nil)
+
+	first := doRequest(r, http.MethodPost, "/api/v1/todos", `{"title":"Once"}`)
+	second := doRequest(r, http.MethodPost, "/api/v1/todos", `{"title":"Once"}`)
+	if first.Code != http.StatusCreated || second.Code != http.StatusCreated {
+		t.Fatalf("statuses %d and %d, want 201", first.Code, second.Code)
+	}
+	if second.Header().Get("X-Deduplicated") != "true" {
+		t.Error("repeat was not marked as deduplicated")
+	}
+	if second.Body.String() != first.Body.String() {
+		t.Errorf("replayed body %s, want %s", second.Body, first.Body)
+	}
+	if got := len(storedTodos()); got != 1 {
+		t.Errorf("stored %d todos, want 1", got)
+	}
+
+	// Per-request headers belong to the repeat, not the first request
+	if first.Header().Get("X-Request-ID") == second.Header().Get("X-Request-ID") {
+		t.Error("replay reused the first request's ID")
+	}
+	if second.Header().Get("Location") != first.Header().Get("Location") {
+		t.Error("replay dropped the handler's Location header")
+	}
+}
+
+func TestDedupDoesNotReplayAcrossCredentials(t *testing.T) {
+	r := newTestRouter(t, map[string]string{
+		"DEDUP_WINDOW":  "1m",
+		"ENABLE_ADMIN":  "true",
+		"ADMIN_API_KEY": "secret",
+	}, nil)
+	t.Cleanup(func() {
+		webhooks.mu.Lock()
+		webhooks.urls = nil
+		webhooks.mu.Unlock()
+	})
+
+	body := `{"url":"https://example.com/hook"}`
+	w := doRequest(r, http.MethodPost, "/api/v1/admin/webhooks", body, "X-Admin-Key", "secret")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("admin call: status %d, body %s", w.Code, w.Body)
+	}
+	w = doRequest(r, http.MethodPost, "/api/v1/admin/webhooks", body)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("call without key: status %d, body %s", w.Code, w.Body)
+	}
+}
+
+func TestDedupDoesNotReplayEncoding(t *testing.T) {
+	r := newTestRouter(t, map[string]string{"DEDUP_WINDOW": "1m", "GZIP_MIN_SIZE": "0"}, nil)
+
+	body := `{"title":"` + strings.Repeat("x", 200) + `"}`
+	w := doRequest(r, http.MethodPost, "/api/v1/todos", body, "Accept-Encoding", "gzip")
+	if w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("first response not compressed: %v", w.Header())
+	}
+	w = doRequest(r, http.MethodPost, "/api/v1/todos", body)
+	if w.Header().Get("Content-Encoding") != "" {
+		t.Errorf("plain request got Content-Encoding %q", w.Header().Get("Content-Encoding"))
+	}
+	if !strings.Contains(w.Body.String(), `"title"`) {
+		t.Errorf("plain request got body %q", w.Body)
+	}
+}
+
+func TestDedupCollapsesConcurrentPuts(t *testing.T) {
+	r := newTestRouter(t, map[string]string{"DEDUP_WINDOW": "1m"}, []Todo{{ID: 1, Title: "Before"}})
+
+	responses := make(chan *httptest.ResponseRecorder, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			responses <- doRequest(r, http.MethodPut, "/api/v1/todos/1", `{"title":"After"}`)
+		}()
+	}
+	first, second := <-responses, <-responses
+
+	if first.Code != http.StatusOK || second.Code != http.StatusOK {
+		t.Fatalf("statuses %d and %d, want 200", first.Code, second.Code)
+	}
+	replays := 0
+	for _, w := range []*httptest.ResponseRecorder{first, second} {
+		if w.Header().Get("X-Deduplicated") == "true" {
+			replays++
+		}
+	}
+	if replays != 1 {
+		t.Errorf("%d responses were replays, want 1", replays)
+	}
+	if first.Body.String() != second.Body.String() {
+		t.Errorf("bodies differ: %s and %s", first.Body, second.Body)
+	}
+
+	todoMu.RLock()
+	updates := len(changeLog)
+	todoMu.RUnlock()
+	if updates != 1 {
+		t.Errorf("%d changes recorded, want one effective write", updates)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// errDestinationNotAllowed reports an outgoing request to an address that
+// is not publicly routable
+var errDestinationNotAllowed = errors.New("destination address is not allowed")
+
+// publicAddr reports whether ip is publicly routable. Loopback, private,
+// link-local, multicast and unspecified addresses are not, so outgoing
+// requests cannot reach this host, its network or cloud metadata services.
+func publicAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast())
+}
+
+// checkDestination is the dialer control of the clients making outgoing
+// requests on a user's behalf. It refuses addresses that are not publicly
+// routable, including those reached through DNS names and redirects.
+func checkDestination(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !publicAddr(ip) {
+		return errDestinationNotAllowed
+	}
+	return nil
+}
+
+// checkDestinationURL refuses an absolute URL whose host is a literal
+// address that is not publicly routable, or a localhost name, so such
+// URLs are turned away before they are stored or fetched. Other names are
+// checked by checkDestination once they resolve.
+func checkDestinationURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return errDestinationNotAllowed
+	}
+	if ip, err := netip.ParseAddr(host); err == nil && !publicAddr(ip) {
+		return errDestinationNotAllowed
+	}
+	return nil
+}
+
+// newPublicClient returns a client that only reaches publicly routable
+// addresses. It dials directly, without any proxy from the environment,
+// so every destination is checked.
+func newPublicClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext: (&net.Dialer{
+				Timeout: timeout,
+				Control: checkDestination,
+			}).DialContext,
+			TLSHandshakeTimeout: timeout,
+		},
+	}
+}
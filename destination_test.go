@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckDestination(t *testing.T) {
+	tests := []struct {
+		address string
+		allowed bool
+	}{
+		{"93.184.216.34:443", true},
+		{"[2606:2800:220:1::]:80", true},
+		{"127.0.0.1:8080", false},
+		{"[::1]:80", false},
+		{"10.1.2.3:80", false},
+		{"192.168.0.1:80", false},
+		{"169.254.169.254:80", false},
+		{"0.0.0.0:80", false},
+		{"[::ffff:127.0.0.1]:80", false},
+		{"[fe80::1]:80", false},
+	}
+	for _, tt := range tests {
+		err := checkDestination("tcp", tt.address, nil)
+		if tt.allowed && err != nil {
+			t.Errorf("%s refused: %v", tt.address, err)
+		}
+		if !tt.allowed && !errors.Is(err, errDestinationNotAllowed) {
+			t.Errorf("%s allowed, err %v", tt.address, err)
+		}
+	}
+}
+
+func TestCheckDestinationURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		allowed bool
+	}{
+		{"https://example.com/hook", true},
+		{"http://93.184.216.34/hook", true},
+		{"http://127.0.0.1:8080/hook", false},
+		{"http://[::1]/hook", false},
+		{"http://169.254.169.254/latest/meta-data", false},
+		{"http://10.0.0.5/hook", false},
+		{"http://localhost:8080/hook", false},
+		{"http://api.localhost./hook", false},
+	}
+	for _, tt := range tests {
+		err := checkDestinationURL(tt.url)
+		if tt.allowed && err != nil {
+			t.Errorf("%s refused: %v", tt.url, err)
+		}
+		if !tt.allowed && !errors.Is(err, errDestinationNotAllowed) {
+			t.Errorf("%s allowed, err %v", tt.url, err)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestDryRunLeavesTodoUntouched(t *testing.T) {
+	tests := []struct {
+		name, method, target, body string
+	}{
+		{"move project", http.MethodPatch, "/api/v1/todos/1/project?dry_run=true", `{"project":"home"}`},
+		{"progress", http.MethodPatch, "/api/v1/todos/1/progress?dry_run=true", `{"progress":50}`},
+		{"snooze", http.MethodPost, "/api/v1/todos/1/snooze?dry_run=true", `{"duration":"1d"}`},
+		{"comment", http.MethodPost, "/api/v1/todos/1/comments?dry_run=true", `{"text":"Later"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRouter(t, nil, []Todo{{ID: 1, Title: "First", Project: "work"}})
+			before := storedTodos()
+			start := storedRevision()
+
+			w := doRequest(r, tt.method, tt.target, tt.body)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status %d, body %s", w.Code, w.Body)
+			}
+			var resp map[string]any
+			decodeBody(t, w, &resp)
+			if resp["dry_run"] != true {
+				t.Errorf("response %s is not marked as a dry run", w.Body)
+			}
+			if after := storedTodos(); !reflect.DeepEqual(after, before) {
+				t.Errorf("store changed from %+v to %+v", before, after)
+			}
+			if storedRevision() != start {
+				t.Error("dry run published a change")
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	// Embed the zone database so tz works in images without one
+	_ "time/tzdata"
+)
+
+// startOfDay returns midnight at the start of t's day in t's location
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// startOfWeek returns midnight on the Monday of t's ISO week
+func startOfWeek(t time.Time) time.Time {
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	return startOfDay(t).AddDate(0, 0, -daysSinceMonday)
+}
+
+// requestNow returns the current time in the time zone named by the tz
+// query parameter, or in the server's when there is none
+func requestNow(c *gin.Context) (time.Time, error) {
+	now := time.Now()
+	if tz := c.Query("tz"); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid tz %q: must be an IANA time zone such as Europe/Berlin", tz)
+		}
+		now = now.In(loc)
+	}
+	return now, nil
+}
+
+// dueMatcher returns a predicate for a relative due-date token, resolved
+// against now in now's location. Todos without a due date never match.
+func dueMatcher(token string, now time.Time) (func(Todo) bool, error) {
+	var start, end time.Time
+	switch token {
+	case "overdue":
+		return func(todo Todo) bool {
+			return todo.DueDate != nil && !todo.Completed && todo.DueDate.Before(now)
+		}, nil
+	case "today":
+		start = startOfDay(now)
+		end = start.AddDate(0, 0, 1)
+	case "tomorrow":
+		start = startOfDay(now).AddDate(0, 0, 1)
+		end = start.AddDate(0, 0, 1)
+	case "this_week":
+		start = startOfWeek(now)
+		end = start.AddDate(0, 0, 7)
+	case "next_week":
+		start = startOfWeek(now).AddDate(0, 0, 7)
+		end = start.AddDate(0, 0, 7)
+	default:
+		return nil, fmt.Errorf("invalid due filter %q: must be today, tomorrow, this_week, next_week or overdue", token)
+	}
+
+	return func(todo Todo) bool {
+		return todo.DueDate != nil && !todo.DueDate.Before(start) && todo.DueDate.Before(end)
+	}, nil
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// exportFlushEvery is how many records are written between flushes
+const exportFlushEvery = 100
+
+// exportChunkSize is how many stored todos an export reads per read lock
+const exportChunkSize = 500
+
+// csvColumn renders one todo field as a CSV cell
+type csvColumn struct {
+	name  string
+	value func(Todo) string
+}
+
+// csvColumns lists the exportable CSV columns in their default order
+var csvColumns = []csvColumn{
+	{"id", func(t Todo) string { return strconv.Itoa(t.ID) }},
+	{"title", func(t Todo) string { return t.Title }},
+	{"description", func(t Todo) string { return t.Description }},
+	{"completed", func(t Todo) string { return strconv.FormatBool(t.Completed) }},
+	{"parent_id", func(t Todo) string {
+		if t.ParentID == nil {
+			return ""
+		}
+		return strconv.Itoa(*t.ParentID)
+	}},
+	{"project", func(t Todo) string { return t.Project }},
+	{"progress", func(t Todo) string { return strconv.Itoa(t.Progress) }},
+	{"due_date", func(t Todo) string {
+		if t.DueDate == nil {
+			return ""
+		}
+		return t.DueDate.Format(time.RFC3339)
+	}},
+	{"priority", func(t Todo) string { return t.Priority }},
+	{"tags", func(t Todo) string { return strings.Join(t.Tags, ";") }},
+	{"created_at", func(t Todo) string { return t.CreatedAt.Format(time.RFC3339) }},
+	{"updated_at", func(t Todo) string { return t.UpdatedAt.Format(time.RFC3339) }},
+}
+
+// parseCSVColumns resolves a comma-separated column list, preserving the
+// requested order. An empty list selects every column.
+func parseCSVColumns(param string) ([]csvColumn, error) {
+	if param == "" {
+		return csvColumns, nil
+	}
+
+	selected := []csvColumn{}
+	for _, name := range strings.Split(param, ",") {
+		name = strings.TrimSpace(name)
+		found := false
+		for _, column := range csvColumns {
+			if column.name == name {
+				selected = append(selected, column)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+	}
+	return selected, nil
+}
+
+// exportTodos calls emit with each todo accepted by matches, in store
+// order. The store is read a chunk at a time under short read locks, so
+// an export neither blocks writers while the client reads nor copies the
+// whole store. Todos changed during the export are sent as they are when
+// their chunk is read. It stops at the first error from emit.
+func exportTodos(matches func(Todo) bool, emit func(Todo) error) error {
+	next, start := 0, 0
+	var scanned []int
+	for {
+		todoMu.RLock()
+		next = resumeIndex(next, start, scanned)
+		start = next
+		end := min(next+exportChunkSize, len(todos))
+		scanned = scanned[:0]
+		chunk := make([]Todo, 0, end-next)
+		for _, todo := range todos[next:end] {
+			scanned = append(scanned, todo.ID)
+			if matches == nil || matches(todo) {
+				chunk = append(chunk, todo)
+			}
+		}
+		next = end
+		done := end == len(todos)
+		todoMu.RUnlock()
+
+		for _, todo := range chunk {
+			if err := emit(todo); err != nil {
+				return err
+			}
+		}
+		if done {
+			return nil
+		}
+	}
+}
+
+// resumeIndex returns where an export continues after reading the chunk
+// that started at start and holds the IDs in scanned, with next just past
+// it. Deletes since then shift the rest of the store down, so the export
+// resumes after the last scanned todo still stored, or at the chunk's
+// start when all of them are gone. Callers must hold todoMu.
+func resumeIndex(next, start int, scanned []int) int {
+	if len(scanned) == 0 {
+		return next
+	}
+	if next <= len(todos) && todos[next-1].ID == scanned[len(scanned)-1] {
+		return next
+	}
+	ids := make(map[int]bool, len(scanned))
+	for _, id := range scanned {
+		ids[id] = true
+	}
+	for i := min(next, len(todos)) - 1; i >= 0; i-- {
+		if ids[todos[i].ID] {
+			return i + 1
+		}
+	}
+	return min(start, len(todos))
+}
+
+// ExportTodos streams every todo matching the filters without pagination
+func ExportTodos(c *gin.Context) {
+	format := c.DefaultQuery("format", "ndjson")
+	switch format {
+	case "ndjson":
+		exportNDJSON(c)
+	case "csv":
+		exportCSV(c)
+	default:
+		respondJSON(c, http.StatusBadRequest, gin.H{"error": "Unsupported export format; use ndjson or csv"})
+	}
+}
+
+// exportNDJSON writes one JSON object per line
+func exportNDJSON(c *gin.Context) {
+	matches, err := todoMatcher(c)
+	if err != nil {
+		respondJSON(c, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Status(http.StatusOK)
+
+	// Encoder.Encode terminates each value with a newline, giving one JSON
+	// object per line; flushing periodically keeps memory flat
+	encoder := json.NewEncoder(c.Writer)
+	written := 0
+	exportTodos(matches, func(todo Todo) error {
+		if err := encoder.Encode(todo); err != nil {
+			return err
+		}
+		written++
+		if written%exportFlushEvery == 0 {
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	c.Writer.Flush()
+}
+
+// exportCSV writes a header row followed by one row per todo, limited to
+// the columns requested in the columns query parameter
+func exportCSV(c *gin.Context) {
+	columns, err := parseCSVColumns(c.Query("columns"))
+	if err != nil {
+		respondJSON(c, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	matches, err := todoMatcher(c)
+	if err != nil {
+		respondJSON(c, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="todos.csv"`)
+	c.Status(http.StatusOK)
+
+	writer := csv.NewWriter(c.Writer)
+	row := make([]string, len(columns))
+	for i, column := range columns {
+		row[i] = column.name
+	}
+	writer.Write(row)
+
+	written := 0
+	exportTodos(matches, func(todo Todo) error {
+		for i, column := range columns {
+			row[i] = column.value(todo)
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+		written++
+		if written%exportFlushEvery == 0 {
+			writer.Flush()
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	writer.Flush()
+	c.Writer.Flush()
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestExportTodosReadsInChunks(t *testing.T) {
+	seed := make([]Todo, 2*exportChunkSize+10)
+	for i := range seed {
+		seed[i] = Todo{ID: i + 1, Title: "Todo", Completed: i%2 == 0}
+	}
+	newTestRouter(t, nil, seed)
+
+	var ids []int
+	exportTodos(func(todo Todo) bool { return !todo.Completed }, func(todo Todo) error {
+		ids = append(ids, todo.ID)
+		return nil
+	})
+	if len(ids) != len(seed)/2 {
+		t.Fatalf("exported %d todos, want %d", len(ids), len(seed)/2)
+	}
+	for i, id := range ids {
+		if id != 2*i+2 {
+			t.Fatalf("todo %d has ID %d, want %d", i, id, 2*i+2)
+		}
+	}
+}
+
+func TestExportTodosResumesAfterDeletes(t *testing.T) {
+	seed := make([]Todo, 2*exportChunkSize)
+	for i := range seed {
+		seed[i] = Todo{ID: i + 1, Title: "Todo"}
+	}
+	newTestRouter(t, nil, seed)
+
+	// Deleting from the first chunk while it is being sent shifts the rest
+	// of the store down; nothing past the chunk may be skipped or repeated
+	seen := map[int]int{}
+	exportTodos(nil, func(todo Todo) error {
+		seen[todo.ID]++
+		if todo.ID == 1 {
+			todoMu.Lock()
+			todos = todos[10:]
+			todoMu.Unlock()
+		}
+		return nil
+	})
+	for id := exportChunkSize + 1; id <= len(seed); id++ {
+		if seen[id] != 1 {
+			t.Fatalf("todo %d exported %d times", id, seen[id])
+		}
+	}
+}
+
+func TestExportTodosStopsOnError(t *testing.T) {
+	newTestRouter(t, nil, []Todo{{ID: 1}, {ID: 2}})
+
+	errStop := errors.New("stop")
+	calls := 0
+	err := exportTodos(nil, func(Todo) error {
+		calls++
+		return errStop
+	})
+	if !errors.Is(err, errStop) || calls != 1 {
+		t.Errorf("error %v after %d calls, want errStop after 1", err, calls)
+	}
+}
+
+func TestExportSkipsRequestTimeout(t *testing.T) {
+	r := newTestRouter(t, map[string]string{"REQUEST_TIMEOUT": "1m"}, []Todo{{ID: 1, Title: "First"}, {ID: 2, Title: "Second"}})
+
+	w := doRequest(r, http.MethodGet, "/api/v1/todos/export", "")
+	if w.Code != http.StatusOK || !w.Flushed {
+		t.Fatalf("status %d, flushed %t; want a streamed 200", w.Code, w.Flushed)
+	}
+	lines := 0
+	for scanner := bufio.NewScanner(strings.NewReader(w.Body.String())); scanner.Scan(); lines++ {
+		var todo Todo
+		if err := json.Unmarshal(scanner.Bytes(), &todo); err != nil {
+			t.Fatalf("line %d: %v", lines+1, err)
+		}
+	}
+	if lines != 2 {
+		t.Errorf("exported %d lines, want 2", lines)
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// featuresHandler reports which optional features cfg enables, so clients
+// can adapt to the server they talk to
+func featuresHandler(cfg Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		respondJSON(c, http.StatusOK, gin.H{
+			"admin":                     cfg.AdminEnabled,
+			"auto_complete_on_progress": cfg.AutoCompleteOnProgress,
+			"concurrency_limit":         cfg.MaxConcurrentRequests > 0,
+			"cors":                      cfg.CORSEnabled,
+			"compaction":                cfg.CompactionInterval > 0,
+			"completed_retention":       cfg.CompletedRetention > 0,
+			"default_sort":              cfg.DefaultSort != nil,
+			"gzip":                      cfg.GzipEnabled,
+			"list_cache":                cfg.ListCacheTTL > 0,
+			"lock_completed":            cfg.LockCompleted,
+			"pretty_json":               cfg.PrettyJSON,
+			"request_dedup":             cfg.DedupWindow > 0,
+			"require_description":       cfg.RequireDescription,
+			"require_subtasks_complete": cfg.RequireSubtasksComplete,
+			"request_timeout":           cfg.RequestTimeout > 0,
+			"response_envelope":         cfg.Envelope,
+			"slow_request_log":          cfg.SlowRequestThreshold > 0,
+			"strict_json":               cfg.StrictJSON,
+			"total_count_cap":           cfg.TotalCountCap > 0,
+			"unprocessable_validation":  cfg.UnprocessableValidation,
+			"upsert_on_put":             cfg.UpsertOnPut,
+			"webhooks":                  len(webhooks.targets()) > 0,
+			"write_queue":               cfg.WriteQueueSize > 0,
+		})
+	}
+}
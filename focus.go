@@ -0,0 +1,89 @@
+package main
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Groups of the focus list, in the order they are listed
+const (
+	focusOverdue = iota
+	focusDueToday
+	focusHighPriority
+)
+
+// focusGroup returns the focus list group of todo at now, or false when it
+// does not belong in the list
+func focusGroup(todo Todo, now time.Time) (int, bool) {
+	if todo.Completed {
+		return 0, false
+	}
+	if todo.DueDate != nil {
+		if todo.DueDate.Before(now) {
+			return focusOverdue, true
+		}
+		if todo.DueDate.Before(startOfDay(now).AddDate(0, 0, 1)) {
+			return focusDueToday, true
+		}
+	}
+	if todo.Priority == "high" {
+		return focusHighPriority, true
+	}
+	return 0, false
+}
+
+// focusTodos picks the incomplete todos to work on today, resolved in now's
+// location: overdue ones first, then those due later today, then any other
+// high priority ones. Within a group, higher priority comes first, then
+// earlier due date, then lower ID.
+func focusTodos(items []Todo, now time.Time) []Todo {
+	type candidate struct {
+		todo  Todo
+		group int
+	}
+	candidates := []candidate{}
+	for _, todo := range items {
+		if group, ok := focusGroup(todo, now); ok {
+			candidates = append(candidates, candidate{todo, group})
+		}
+	}
+
+	slices.SortFunc(candidates, func(a, b candidate) int {
+		if a.group != b.group {
+			return cmp.Compare(a.group, b.group)
+		}
+		if byPriority := cmp.Compare(priorityRanks[b.todo.Priority], priorityRanks[a.todo.Priority]); byPriority != 0 {
+			return byPriority
+		}
+		if byDue := compareOptionalTimes(a.todo.DueDate, b.todo.DueDate); byDue != 0 {
+			return byDue
+		}
+		return cmp.Compare(a.todo.ID, b.todo.ID)
+	})
+
+	focus := make([]Todo, len(candidates))
+	for i, c := range candidates {
+		focus[i] = c.todo
+	}
+	return focus
+}
+
+// GetFocusTodos returns the focus list for today, with "today" taken in
+// the time zone named by tz
+func GetFocusTodos(c *gin.Context) {
+	now, err := requestNow(c)
+	if err != nil {
+		respondJSON(c, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	todoMu.RLock()
+	focus := focusTodos(todos, now)
+	todoMu.RUnlock()
+
+	respondJSON(c, http.StatusOK, gin.H{listKey: focus})
+}
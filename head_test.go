@@ -0,0 +1,51 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestHeadMatchesGet(t *testing.T) {
+	seed := []Todo{{ID: 1, Title: "First"}, {ID: 2, Title: "Second", Completed: true}}
+	tests := []struct {
+		target string
+		accept string
+	}{
+		{"/api/v1/todos", ""},
+		{"/api/v1/todos?completed=false", "application/xml"},
+		{"/api/v1/todos/1", ""},
+		{"/api/v1/todos/2", "application/xml"},
+	}
+	for _, tt := range tests {
+		r := newTestRouter(t, nil, seed)
+		get := doRequest(r, http.MethodGet, tt.target, "", "Accept", tt.accept)
+		head := doRequest(r, http.MethodHead, tt.target, "", "Accept", tt.accept)
+
+		if head.Code != get.Code || head.Body.Len() != 0 {
+			t.Errorf("HEAD %s: status %d, body %q, want %d and no body", tt.target, head.Code, head.Body, get.Code)
+		}
+		for _, name := range []string{"Content-Type", "ETag", "X-Revision"} {
+			if head.Header().Get(name) != get.Header().Get(name) {
+				t.Errorf("HEAD %s: %s = %q, GET sent %q", tt.target, name, head.Header().Get(name), get.Header().Get(name))
+			}
+		}
+	}
+}
+
+func TestHeadTodosCountsMatches(t *testing.T) {
+	r := newTestRouter(t, nil, []Todo{{ID: 1, Title: "First"}, {ID: 2, Title: "Second", Completed: true}})
+
+	w := doRequest(r, http.MethodHead, "/api/v1/todos?completed=false", "")
+	if w.Code != http.StatusOK || w.Header().Get("X-Total-Count") != "1" {
+		t.Errorf("status %d, X-Total-Count %q, want 200 and 1", w.Code, w.Header().Get("X-Total-Count"))
+	}
+}
+
+func TestHeadTodoNotFound(t *testing.T) {
+	r := newTestRouter(t, nil, []Todo{{ID: 1, Title: "First"}})
+
+	w := doRequest(r, http.MethodHead, "/api/v1/todos/9", "")
+	if w.Code != http.StatusNotFound || w.Body.Len() != 0 {
+		t.Errorf("status %d, body %q, want 404 and no body", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// probeTodoID marks the throwaway todo written by the deep health check.
+// Real IDs are always positive, so it cannot collide with one.
+const probeTodoID = -1
+
+// deepHealthInterval is the minimum time between two store round trips;
+// checks in between get the last result
+const deepHealthInterval = time.Second
+
+// storeLockTimeout is how long the deep health check waits for the store
+// lock before reporting the store as stuck
+var storeLockTimeout = 2 * time.Second
+
+// errStoreLocked reports a store lock that could not be taken in time
+var errStoreLocked = errors.New("store lock not acquired in time")
+
+// lockStore takes the write lock on the store, giving up after timeout
+func lockStore(timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for !todoMu.TryLock() {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return true
+}
+
+// storeRoundTrip checks that the store lock can be taken, that every
+// stored ID is unique and below nextID so the next create cannot collide,
+// and then writes a throwaway todo, reads it back and deletes it, all
+// under one write lock so no request ever sees it
+func storeRoundTrip() error {
+	if !lockStore(storeLockTimeout) {
+		return errStoreLocked
+	}
+	defer todoMu.Unlock()
+
+	seen := make(map[int]bool, len(todos))
+	for _, todo := range todos {
+		if seen[todo.ID] {
+			return fmt.Errorf("todo ID %d is stored twice", todo.ID)
+		}
+		if todo.ID >= nextID {
+			return fmt.Errorf("todo ID %d is not below the next ID %d", todo.ID, nextID)
+		}
+		seen[todo.ID] = true
+	}
+
+	probe := Todo{ID: probeTodoID, Title: "health probe", CreatedAt: time.Now()}
+	probe.UpdatedAt = probe.CreatedAt
+	todos = append(todos, probe)
+
+	i := findTodoIndex(probeTodoID)
+	if i == -1 {
+		return errors.New("written todo could not be read back")
+	}
+	if todos[i].Title != probe.Title || !todos[i].CreatedAt.Equal(probe.CreatedAt) {
+		todos = slices.Delete(todos, i, i+1)
+		return errors.New("todo read back differs from the one written")
+	}
+
+	todos = slices.Delete(todos, i, i+1)
+	if findTodoIndex(probeTodoID) != -1 {
+		return errors.New("written todo could not be deleted")
+	}
+	return nil
+}
+
+// deepHealthCheck runs check at most once per interval, so the endpoint
+// cannot be used to hammer the store
+type deepHealthCheck struct {
+	check    func() error
+	interval time.Duration
+
+	mu      sync.Mutex
+	lastRun time.Time
+	lastErr error
+}
+
+// run returns the result of check, reusing the last one within interval
+func (h *deepHealthCheck) run() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.lastRun.IsZero() || time.Since(h.lastRun) >= h.interval {
+		h.lastErr = h.check()
+		h.lastRun = time.Now()
+	}
+	return h.lastErr
+}
+
+// Handler answers 200 when the store round trip succeeds and 503 with the
+// failure otherwise
+func (h *deepHealthCheck) Handler(c *gin.Context) {
+	if err := h.run(); err != nil {
+		respondJSON(c, http.StatusServiceUnavailable, gin.H{
+			"status": "unhealthy",
+			"error":  err.Error(),
+		})
+		return
+	}
+	respondJSON(c, http.StatusOK, gin.H{"status": "healthy"})
+}
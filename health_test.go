@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestDeepHealthCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		check  func() error
+		status int
+	}{
+		{"healthy", func() error { return nil }, http.StatusOK},
+		{"failing store", func() error { return errors.New("store unavailable") }, http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.GET("/health/deep", (&deepHealthCheck{check: tt.check, interval: time.Minute}).Handler)
+			w := doRequest(r, http.MethodGet, "/health/deep", "")
+			if w.Code != tt.status {
+				t.Errorf("status %d, want %d, body %s", w.Code, tt.status, w.Body)
+			}
+		})
+	}
+}
+
+func TestStoreRoundTrip(t *testing.T) {
+	newTestRouter(t, nil, []Todo{{ID: 1, Title: "First"}})
+	if err := storeRoundTrip(); err != nil {
+		t.Fatalf("healthy store: %v", err)
+	}
+	if len(storedTodos()) != 1 {
+		t.Errorf("probe todo was left behind: %+v", storedTodos())
+	}
+
+	// A duplicate ID would make lookups and updates hit the wrong todo
+	todoMu.Lock()
+	todos = append(todos, Todo{ID: 1, Title: "Copy"})
+	todoMu.Unlock()
+	if err := storeRoundTrip(); err == nil || !strings.Contains(err.Error(), "stored twice") {
+		t.Errorf("duplicate ID: error %v", err)
+	}
+}
+
+func TestDeepHealthReportsStuckStore(t *testing.T) {
+	r := newTestRouter(t, nil, nil)
+	defer func(timeout time.Duration) { storeLockTimeout = timeout }(storeLockTimeout)
+	storeLockTimeout = 20 * time.Millisecond
+
+	todoMu.Lock()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/deep", nil))
+	todoMu.Unlock()
+
+	if w.Code != http.StatusServiceUnavailable || !strings.Contains(w.Body.String(), errStoreLocked.Error()) {
+		t.Errorf("status %d, body %s", w.Code, w.Body)
+	}
+}
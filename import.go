@@ -0,0 +1,332 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// Limits on fetching a remote import
+const (
+	importTimeout  = 10 * time.Second
+	importMaxBytes = 1 << 20
+)
+
+// errImportTooLarge reports a remote import over importMaxBytes
+var errImportTooLarge = fmt.Errorf("remote file exceeds %d bytes", importMaxBytes)
+
+// importClient fetches remote imports. It dials directly, without any
+// proxy from the environment, so every destination is checked. Tests
+// replace it with a client that may reach a local server.
+var importClient = newPublicClient(importTimeout)
+
+// ImportURLRequest is the body accepted when importing todos from a URL.
+// Format is detected from the response when left out.
+type ImportURLRequest struct {
+	URL    string `json:"url" binding:"required"`
+	Format string `json:"format" binding:"omitempty,oneof=json ndjson csv"`
+}
+
+// importError reports a record that could not be imported, numbered from 1
+// in the order it appears in the file
+type importError struct {
+	Record int    `json:"record"`
+	Error  string `json:"error"`
+}
+
+// fetchImport downloads target, failing when it answers with an error
+// status or sends more than importMaxBytes. It returns the body and its
+// media type.
+func fetchImport(target string) ([]byte, string, error) {
+	resp, err := importClient.Get(target)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, "", fmt.Errorf("remote server answered %s", resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, importMaxBytes+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(body) > importMaxBytes {
+		return nil, "", errImportTooLarge
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return body, mediaType, nil
+}
+
+// detectImportFormat picks json, ndjson or csv from the media type, falling
+// back to sniffing the body
+func detectImportFormat(body []byte, mediaType string) string {
+	switch mediaType {
+	case "text/csv":
+		return "csv"
+	case "application/x-ndjson":
+		return "ndjson"
+	}
+	trimmed := bytes.TrimSpace(body)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("[")):
+		return "json"
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		return "ndjson"
+	}
+	return "csv"
+}
+
+// parseImport reads the records of body in format. Records that cannot be
+// parsed are reported as errors rather than failing the whole import.
+func parseImport(body []byte, format string) ([]TodoInput, []importError, error) {
+	switch format {
+	case "json":
+		return parseJSONImport(body)
+	case "ndjson":
+		return parseNDJSONImport(body)
+	default:
+		return parseCSVImport(body)
+	}
+}
+
+// parseJSONImport reads a JSON array of todos
+func parseJSONImport(body []byte) ([]TodoInput, []importError, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+	inputs := make([]TodoInput, len(raw))
+	var errs []importError
+	for i, record := range raw {
+		if err := json.Unmarshal(record, &inputs[i]); err != nil {
+			errs = append(errs, importError{Record: i + 1, Error: err.Error()})
+		}
+	}
+	return inputs, errs, nil
+}
+
+// parseNDJSONImport reads one todo per line, as written by the ndjson
+// export. Blank lines are skipped.
+func parseNDJSONImport(body []byte) ([]TodoInput, []importError, error) {
+	var inputs []TodoInput
+	var errs []importError
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(nil, importMaxBytes)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var input TodoInput
+		if err := json.Unmarshal(line, &input); err != nil {
+			errs = append(errs, importError{Record: len(inputs) + 1, Error: err.Error()})
+		}
+		inputs = append(inputs, input)
+	}
+	return inputs, errs, scanner.Err()
+}
+
+// csvImportFields sets a TodoInput field from a CSV cell, keyed by the
+// column names of the csv export. Columns not listed, such as id and the
+// timestamps, are ignored.
+var csvImportFields = map[string]func(in *TodoInput, cell string) error{
+	"title":       func(in *TodoInput, cell string) error { in.Title = cell; return nil },
+	"description": func(in *TodoInput, cell string) error { in.Description = cell; return nil },
+	"project":     func(in *TodoInput, cell string) error { in.Project = cell; return nil },
+	"priority":    func(in *TodoInput, cell string) error { in.Priority = cell; return nil },
+	"completed": func(in *TodoInput, cell string) (err error) {
+		if cell != "" {
+			in.Completed, err = strconv.ParseBool(cell)
+		}
+		return err
+	},
+	"progress": func(in *TodoInput, cell string) (err error) {
+		if cell != "" {
+			in.Progress, err = strconv.Atoi(cell)
+		}
+		return err
+	},
+	"due_date": func(in *TodoInput, cell string) error {
+		if cell == "" {
+			return nil
+		}
+		due, err := time.Parse(time.RFC3339, cell)
+		in.DueDate = &due
+		return err
+	},
+	"tags": func(in *TodoInput, cell string) error {
+		if cell != "" {
+			in.Tags = strings.Split(cell, ";")
+		}
+		return nil
+	},
+}
+
+// parseCSVImport reads a header row naming the columns followed by one todo
+// per row, as written by the csv export
+func parseCSVImport(body []byte) ([]TodoInput, []importError, error) {
+	reader := csv.NewReader(bytes.NewReader(body))
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid CSV: %v", err)
+	}
+
+	var inputs []TodoInput
+	var errs []importError
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid CSV: %v", err)
+		}
+
+		var input TodoInput
+		for i, name := range header {
+			set, ok := csvImportFields[strings.TrimSpace(name)]
+			if !ok || i >= len(row) {
+				continue
+			}
+			if err := set(&input, row[i]); err != nil {
+				errs = append(errs, importError{Record: len(inputs) + 1, Error: fmt.Sprintf("%s: %v", name, err)})
+				break
+			}
+		}
+		inputs = append(inputs, input)
+	}
+	return inputs, errs, nil
+}
+
+// importTodo validates an imported record like a create body and returns
+// it as a new todo
+func importTodo(input TodoInput) (Todo, error) {
+	if err := binding.Validator.ValidateStruct(&input); err != nil {
+		return Todo{}, err
+	}
+	if err := checkText(input); err != nil {
+		return Todo{}, err
+	}
+	todo := input.toTodo()
+	// Parent IDs refer to the source's todos, not this server's
+	todo.ParentID = nil
+
+	var err error
+	if todo.Tags, err = normalizeTags(todo.Tags); err != nil {
+		return Todo{}, err
+	}
+	return todo, nil
+}
+
+// ImportTodosFromURL fetches a JSON, NDJSON or CSV file in the format of
+// the export from an http or https URL and creates a todo for each valid
+// record. Invalid records are skipped and reported. A dry run reports the
+// same counts without creating anything.
+func ImportTodosFromURL(c *gin.Context) {
+	var req ImportURLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondJSON(c, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !validHTTPURL(req.URL) {
+		respondJSON(c, http.StatusBadRequest, gin.H{"error": "url must be an absolute http or https URL"})
+		return
+	}
+
+	body, mediaType, err := fetchImport(req.URL)
+	if errors.Is(err, errImportTooLarge) {
+		respondJSON(c, http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, errDestinationNotAllowed) {
+		respondJSON(c, http.StatusBadRequest, gin.H{"error": "url must not point to a loopback, private or link-local address"})
+		return
+	}
+	if err != nil {
+		// The cause stays in the log so responses do not reveal what
+		// remote servers answered
+		log.Printf("import: fetching %s failed: %v", req.URL, err)
+		respondJSON(c, http.StatusBadGateway, gin.H{"error": "Could not fetch import"})
+		return
+	}
+	format := req.Format
+	if format == "" {
+		format = detectImportFormat(body, mediaType)
+	}
+
+	inputs, errs, err := parseImport(body, format)
+	if err != nil {
+		respondJSON(c, http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	failed := map[int]bool{}
+	for _, e := range errs {
+		failed[e.Record] = true
+	}
+
+	imported := []Todo{}
+	for i, input := range inputs {
+		if failed[i+1] {
+			continue
+		}
+		todo, err := importTodo(input)
+		if err != nil {
+			errs = append(errs, importError{Record: i + 1, Error: err.Error()})
+			continue
+		}
+		imported = append(imported, todo)
+	}
+	if errs == nil {
+		errs = []importError{}
+	}
+	slices.SortFunc(errs, func(a, b importError) int { return cmp.Compare(a.Record, b.Record) })
+
+	todoMu.Lock()
+	defer todoMu.Unlock()
+
+	if !idsAvailable(len(imported)) {
+		respondJSON(c, http.StatusInsufficientStorage, gin.H{"error": errIDsExhausted})
+		return
+	}
+
+	if isDryRun(c) {
+		respondJSON(c, http.StatusOK, gin.H{"dry_run": true, "format": format, "imported": len(imported), "errors": errs})
+		return
+	}
+
+	now := time.Now()
+	for i := range imported {
+		applyProgress(&imported[i])
+		trackCompletion(&imported[i], false, now)
+		imported[i].ID = nextID
+		imported[i].CreatedAt = now
+		imported[i].UpdatedAt = now
+		nextID++
+	}
+	todos = append(todos, imported...)
+	if len(imported) > 0 {
+		invalidateListCache()
+	}
+	for _, todo := range imported {
+		publishChange(eventTodoCreated, todo)
+	}
+
+	respondJSON(c, http.StatusOK, gin.H{"format": format, "imported": len(imported), "errors": errs})
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestImportFromURLRefusesLoopback(t *testing.T) {
+	r := newTestRouter(t, nil, nil)
+
+	w := doRequest(r, http.MethodPost, "/api/v1/todos/import-url", `{"url":"http://127.0.0.1:1/todos.json"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status %d, body %s", w.Code, w.Body)
+	}
+}
+
+// serveImport starts a local server answering every request with status,
+// contentType and body, and lets importClient reach it for the test
+func serveImport(t *testing.T, status int, contentType, body string) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+
+	client := importClient
+	importClient = server.Client()
+	t.Cleanup(func() { importClient = client })
+	return server.URL
+}
+
+// importResponse is the body of a URL import
+type importResponse struct {
+	DryRun   bool          `json:"dry_run"`
+	Format   string        `json:"format"`
+	Imported int           `json:"imported"`
+	Errors   []importError `json:"errors"`
+}
+
+func TestImportFromURL(t *testing.T) {
+	csv := "title,completed,tags\nFirst,false,work\nSecond,true,\nThird,maybe,\n"
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		format      string
+	}{
+		{"json", "application/json", `[{"title":"First","tags":["work"]},{"title":"Second","completed":true},{"title":"Third","priority":"urgent"}]`, "json"},
+		{"ndjson", "application/x-ndjson", "{\"title\":\"First\",\"tags\":[\"work\"]}\n{\"title\":\"Second\",\"completed\":true}\nnot json\n", "ndjson"},
+		{"csv", "text/csv", csv, "csv"},
+		{"sniffed csv", "text/plain", csv, "csv"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRouter(t, nil, nil)
+			target := serveImport(t, http.StatusOK, tt.contentType, tt.body)
+
+			w := doRequest(r, http.MethodPost, "/api/v1/todos/import-url", `{"url":"`+target+`/todos"}`)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status %d, body %s", w.Code, w.Body)
+			}
+			var resp importResponse
+			decodeBody(t, w, &resp)
+			if resp.Format != tt.format || resp.Imported != 2 || len(resp.Errors) != 1 || resp.Errors[0].Record != 3 {
+				t.Errorf("response %+v", resp)
+			}
+
+			stored := storedTodos()
+			if len(stored) != 2 || stored[0].Title != "First" || !slices.Equal(stored[0].Tags, []string{"work"}) || !stored[1].Completed {
+				t.Errorf("stored %+v", stored)
+			}
+		})
+	}
+}
+
+func TestImportFromURLDryRun(t *testing.T) {
+	r := newTestRouter(t, nil, nil)
+	target := serveImport(t, http.StatusOK, "application/json", `[{"title":"First"}]`)
+
+	w := doRequest(r, http.MethodPost, "/api/v1/todos/import-url?dry_run=true", `{"url":"`+target+`"}`)
+	var resp importResponse
+	decodeBody(t, w, &resp)
+	if w.Code != http.StatusOK || !resp.DryRun || resp.Imported != 1 {
+		t.Errorf("status %d, response %+v", w.Code, resp)
+	}
+	if got := len(storedTodos()); got != 0 {
+		t.Errorf("dry run stored %d todos", got)
+	}
+}
+
+func TestImportFromURLFailures(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   int
+	}{
+		{"error status", http.StatusNotFound, "missing", http.StatusBadGateway},
+		{"oversized body", http.StatusOK, "[" + strings.Repeat(" ", importMaxBytes) + "]", http.StatusUnprocessableEntity},
+		{"unparseable body", http.StatusOK, "[{", http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRouter(t, nil, nil)
+			target := serveImport(t, tt.status, "application/json", tt.body)
+
+			w := doRequest(r, http.MethodPost, "/api/v1/todos/import-url", `{"url":"`+target+`","format":"json"}`)
+			if w.Code != tt.want {
+				t.Errorf("status %d, want %d, body %s", w.Code, tt.want, w.Body)
+			}
+			if strings.Contains(w.Body.String(), "404") {
+				t.Errorf("response reveals the remote status: %s", w.Body)
+			}
+			if got := len(storedTodos()); got != 0 {
+				t.Errorf("stored %d todos", got)
+			}
+		})
+	}
+}
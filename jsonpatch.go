@@ -0,0 +1,226 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// jsonPatchContentType is the media type of RFC 6902 JSON Patch bodies
+const jsonPatchContentType = "application/json-patch+json"
+
+// PatchOperation is one operation of an RFC 6902 JSON Patch. Only add,
+// replace, remove and test are supported, on the top-level client-settable
+// fields.
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// errPatchTestFailed reports a test operation whose value did not match
+var errPatchTestFailed = errors.New("test operation failed")
+
+// patchDocument renders the client-settable fields of todo as the JSON
+// object patches are applied to
+func patchDocument(todo Todo) (map[string]json.RawMessage, error) {
+	input := TodoInput{
+		Title:       todo.Title,
+		Description: todo.Description,
+		Completed:   todo.Completed,
+		ParentID:    todo.ParentID,
+		Project:     todo.Project,
+		Progress:    todo.Progress,
+		DueDate:     todo.DueDate,
+		Priority:    todo.Priority,
+		Tags:        todo.Tags,
+	}
+	data, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	delete(doc, "update_mask")
+	return doc, nil
+}
+
+// patchField returns the field named by a JSON Pointer path such as
+// /title, which must be one of the client-settable fields
+func patchField(path string) (string, error) {
+	field, ok := strings.CutPrefix(path, "/")
+	if !ok || maskFields[field] == nil {
+		return "", fmt.Errorf("invalid path %q: must be / followed by one of the todo's settable fields", path)
+	}
+	return field, nil
+}
+
+// jsonEqual reports whether two JSON values are equal once decoded
+func jsonEqual(a, b json.RawMessage) bool {
+	var va, vb any
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+// applyJSONPatch applies ops to doc in order. Removing a field resets it
+// to its empty value. It returns errPatchTestFailed when a test operation
+// does not match, and a descriptive error for any invalid operation.
+func applyJSONPatch(doc map[string]json.RawMessage, ops []PatchOperation) error {
+	for n, op := range ops {
+		field, err := patchField(op.Path)
+		if err != nil {
+			return fmt.Errorf("operation %d: %v", n, err)
+		}
+		switch op.Op {
+		case "add", "replace":
+			if op.Value == nil {
+				return fmt.Errorf("operation %d: %s requires a value", n, op.Op)
+			}
+			doc[field] = op.Value
+		case "remove":
+			doc[field] = json.RawMessage("null")
+		case "test":
+			if op.Value == nil {
+				return fmt.Errorf("operation %d: test requires a value", n)
+			}
+			if !jsonEqual(doc[field], op.Value) {
+				return fmt.Errorf("operation %d: %w: %s does not match", n, errPatchTestFailed, op.Path)
+			}
+		default:
+			return fmt.Errorf("operation %d: unsupported op %q: must be add, replace, remove or test", n, op.Op)
+		}
+	}
+	return nil
+}
+
+// applyMergePatch copies the fields of a partial update into doc. A null
+// value resets the field to its empty value, and fields left out are kept.
+func applyMergePatch(doc, fields map[string]json.RawMessage) error {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if maskFields[name] == nil {
+			return fmt.Errorf("invalid field %q: must be one of the todo's settable fields", name)
+		}
+		doc[name] = fields[name]
+	}
+	return nil
+}
+
+// PatchTodo partially updates a todo. An application/json-patch+json body
+// is applied as a JSON Patch, and an application/json body as a partial
+// update of the fields it names. The patched todo is validated like an
+// update body before it replaces the stored one, and a failed test
+// operation leaves the todo untouched.
+func PatchTodo(c *gin.Context) {
+	id, ok := parseTodoID(c)
+	if !ok {
+		return
+	}
+
+	var apply func(doc map[string]json.RawMessage) error
+	switch c.ContentType() {
+	case jsonPatchContentType:
+		var ops []PatchOperation
+		if !bindPatchBody(c, &ops) {
+			return
+		}
+		apply = func(doc map[string]json.RawMessage) error { return applyJSONPatch(doc, ops) }
+	case "application/json":
+		var fields map[string]json.RawMessage
+		if !bindPatchBody(c, &fields) {
+			return
+		}
+		apply = func(doc map[string]json.RawMessage) error { return applyMergePatch(doc, fields) }
+	default:
+		respondJSON(c, http.StatusUnsupportedMediaType, gin.H{"error": "Content-Type must be " + jsonPatchContentType + " or application/json"})
+		return
+	}
+
+	todoMu.Lock()
+	defer todoMu.Unlock()
+
+	i := findTodoIndex(id)
+	if i == -1 {
+		respondJSON(c, http.StatusNotFound, gin.H{"error": "Todo not found"})
+		return
+	}
+
+	doc, err := patchDocument(todos[i])
+	if err != nil {
+		respondJSON(c, http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := apply(doc); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, errPatchTestFailed) {
+			status = http.StatusConflict
+		}
+		respondJSON(c, status, gin.H{"error": err.Error()})
+		return
+	}
+
+	patched, err := json.Marshal(doc)
+	if err != nil {
+		respondJSON(c, http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	var input TodoInput
+	if err := json.Unmarshal(patched, &input); err != nil {
+		respondJSON(c, validationStatus(), gin.H{"error": err.Error()})
+		return
+	}
+	if err := binding.Validator.ValidateStruct(&input); err != nil {
+		respondJSON(c, validationStatus(), gin.H{"error": err.Error()})
+		return
+	}
+	if err := checkText(input); err != nil {
+		respondJSON(c, validationStatus(), gin.H{"error": err.Error()})
+		return
+	}
+	updatedTodo := input.toTodo()
+	if updatedTodo.Tags, err = normalizeTags(updatedTodo.Tags); err != nil {
+		respondJSON(c, validationStatus(), gin.H{"error": err.Error()})
+		return
+	}
+	if updatedTodo.ParentID != nil {
+		if findTodoIndex(*updatedTodo.ParentID) == -1 {
+			respondJSON(c, validationStatus(), gin.H{"error": "Parent todo not found"})
+			return
+		}
+		if createsCycle(id, *updatedTodo.ParentID) {
+			respondJSON(c, validationStatus(), gin.H{"error": "Parent would create a cycle"})
+			return
+		}
+	}
+
+	replaceTodo(c, i, updatedTodo)
+}
+
+// bindPatchBody decodes a required PATCH body into dst, responding with
+// 400 and returning false when it is missing or malformed
+func bindPatchBody(c *gin.Context, dst any) bool {
+	if err := requireBody(c); err != nil {
+		respondJSON(c, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	if err := c.ShouldBindJSON(dst); err != nil {
+		respondJSON(c, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestPatchTodo(t *testing.T) {
+	due := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	seed := []Todo{{ID: 1, Title: "Buy milk", Description: "Corner shop", Priority: "low", DueDate: &due}}
+	tests := []struct {
+		name, contentType, body string
+		status                  int
+		title, description      string
+		dueCleared              bool
+	}{
+		{"merge", "application/json", `{"title":"Buy oat milk","due_date":null}`, http.StatusOK, "Buy oat milk", "Corner shop", true},
+		{"merge unknown field", "application/json", `{"id":7}`, http.StatusBadRequest, "Buy milk", "Corner shop", false},
+		{"merge invalid value", "application/json", `{"priority":"urgent"}`, http.StatusBadRequest, "Buy milk", "Corner shop", false},
+		{"json patch", jsonPatchContentType,
+			`[{"op":"test","path":"/title","value":"Buy milk"},{"op":"replace","path":"/description","value":"Market"},{"op":"remove","path":"/due_date"}]`,
+			http.StatusOK, "Buy milk", "Market", true},
+		{"json patch failed test", jsonPatchContentType, `[{"op":"test","path":"/title","value":"Other"}]`, http.StatusConflict, "Buy milk", "Corner shop", false},
+		{"json patch unknown path", jsonPatchContentType, `[{"op":"replace","path":"/id","value":7}]`, http.StatusBadRequest, "Buy milk", "Corner shop", false},
+		{"other content type", "text/plain", `title=x`, http.StatusUnsupportedMediaType, "Buy milk", "Corner shop", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRouter(t, nil, seed)
+			w := doRequest(r, http.MethodPatch, "/api/v1/todos/1", tt.body, "Content-Type", tt.contentType)
+			if w.Code != tt.status {
+				t.Fatalf("status %d, want %d, body %s", w.Code, tt.status, w.Body)
+			}
+			stored := storedTodos()[0]
+			if stored.Title != tt.title || stored.Description != tt.description || (stored.DueDate == nil) != tt.dueCleared {
+				t.Errorf("stored %+v", stored)
+			}
+			if stored.Priority != "low" {
+				t.Errorf("priority %q was not kept", stored.Priority)
+			}
+		})
+	}
+}
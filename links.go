@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// linksProfile is the Accept profile asking for _links on list items, as
+// in Accept: application/json; profile="links"
+const linksProfile = "links"
+
+// link is a hypermedia link to a todo operation
+type link struct {
+	Href   string `json:"href" xml:"href,attr"`
+	Method string `json:"method" xml:"method,attr"`
+}
+
+// todoLinks are the operations a client can follow from a todo
+type todoLinks struct {
+	Self   link `json:"self" xml:"self"`
+	Update link `json:"update" xml:"update"`
+	Delete link `json:"delete" xml:"delete"`
+}
+
+// linkedTodo is a todo rendered with its _links
+type linkedTodo struct {
+	Todo
+	Links todoLinks
+}
+
+func (t linkedTodo) MarshalJSON() ([]byte, error) {
+	type todoFields Todo
+	return json.Marshal(struct {
+		todoFields
+		TimeRemaining *int64    `json:"time_remaining,omitempty"`
+		Links         todoLinks `json:"_links"`
+	}{todoFields(t.Todo), t.timeRemaining(), t.Links})
+}
+
+func (t linkedTodo) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type todoFields Todo
+	return e.EncodeElement(struct {
+		todoFields
+		TimeRemaining *int64    `xml:"time_remaining,omitempty"`
+		Links         todoLinks `xml:"_links"`
+	}{todoFields(t.Todo), t.timeRemaining(), t.Links}, start)
+}
+
+// acceptsProfile reports whether the Accept header asks for profile on any
+// of its media ranges
+func acceptsProfile(c *gin.Context, profile string) bool {
+	for _, part := range strings.Split(c.GetHeader("Accept"), ",") {
+		_, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err == nil && params["profile"] == profile {
+			return true
+		}
+	}
+	return false
+}
+
+// wantsLinks reports whether list items should carry _links, requested
+// with links=true or the links Accept profile
+func wantsLinks(c *gin.Context) bool {
+	return c.Query("links") == "true" || acceptsProfile(c, linksProfile)
+}
+
+// withLinks returns items with links below collectionPath, the path the
+// list was requested at, so they follow any prefix the API is served under
+func withLinks(items []Todo, collectionPath string) []linkedTodo {
+	linked := make([]linkedTodo, len(items))
+	for i, todo := range items {
+		href := fmt.Sprintf("%s/%d", collectionPath, todo.ID)
+		linked[i] = linkedTodo{Todo: todo, Links: todoLinks{
+			Self:   link{Href: href, Method: "GET"},
+			Update: link{Href: href, Method: "PUT"},
+			Delete: link{Href: href, Method: "DELETE"},
+		}}
+	}
+	return linked
+}
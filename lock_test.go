@@ -0,0 +1,44 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLockCompletedGuardsEdits(t *testing.T) {
+	due := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	parent := 2
+	seed := []Todo{
+		{ID: 1, Title: "Done", Completed: true, DueDate: &due},
+		{ID: 2, Title: "Open", Description: "Details"},
+		{ID: 3, Title: "Done subtask", Completed: true, ParentID: &parent},
+		{ID: 4, Title: "Another open"},
+	}
+	tests := []struct {
+		name, method, target, body string
+	}{
+		{"snooze", http.MethodPost, "/api/v1/todos/1/snooze", `{"duration":"1d"}`},
+		{"comment", http.MethodPost, "/api/v1/todos/1/comments", `{"text":"Reopen?"}`},
+		{"merge into completed", http.MethodPost, "/api/v1/todos/2/merge", `{"into":1}`},
+		{"merge moving completed subtask", http.MethodPost, "/api/v1/todos/2/merge", `{"into":4}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRouter(t, map[string]string{"LOCK_COMPLETED": "true"}, seed)
+			w := doRequest(r, tt.method, tt.target, tt.body)
+			if w.Code != http.StatusConflict {
+				t.Errorf("status %d, body %s", w.Code, w.Body)
+			}
+			if !reflect.DeepEqual(storedTodos(), seed) {
+				t.Errorf("store changed to %+v", storedTodos())
+			}
+		})
+	}
+
+	r := newTestRouter(t, nil, seed)
+	if w := doRequest(r, http.MethodPost, "/api/v1/todos/1/comments", `{"text":"Fine"}`); w.Code != http.StatusCreated {
+		t.Errorf("comment without the lock: status %d, body %s", w.Code, w.Body)
+	}
+}
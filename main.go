@@ -1,123 +1,927 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
 	"net/http"
+	"os"
+	"os/signal"
+	"path"
+	"slices"
 	"strconv"
+	"strings"
 	"sync"
+	"syscall"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
 )
 
 // Todo represents a todo item
 type Todo struct {
-	ID          int       `json:"id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Completed   bool      `json:"completed"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          int        `json:"id" xml:"id"`
+	Title       string     `json:"title" xml:"title"`
+	Description string     `json:"description" xml:"description"`
+	Completed   bool       `json:"completed" xml:"completed"`
+	ParentID    *int       `json:"parent_id,omitempty" xml:"parent_id,omitempty"`
+	Project     string     `json:"project,omitempty" xml:"project,omitempty"`
+	Progress    int        `json:"progress" xml:"progress"`
+	DueDate     *time.Time `json:"due_date,omitempty" xml:"due_date,omitempty"`
+	Priority    string     `json:"priority,omitempty" xml:"priority,omitempty"`
+	Tags        []string   `json:"tags,omitempty" xml:"tags>tag,omitempty"`
+	Comments    []Comment  `json:"comments,omitempty" xml:"comments>comment,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty" xml:"completed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at" xml:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" xml:"updated_at"`
 }
 
+// timeRemaining returns the number of seconds until the due date, negative
+// once overdue, or nil when the todo has no due date
+func (t Todo) timeRemaining() *int64 {
+	if t.DueDate == nil {
+		return nil
+	}
+	remaining := int64(time.Until(*t.DueDate) / time.Second)
+	return &remaining
+}
+
+// MarshalJSON adds the derived time_remaining field, computed at
+// serialization time
+func (t Todo) MarshalJSON() ([]byte, error) {
+	type todoFields Todo
+	return json.Marshal(struct {
+		todoFields
+		TimeRemaining *int64 `json:"time_remaining,omitempty"`
+	}{todoFields(t), t.timeRemaining()})
+}
+
+// MarshalXML mirrors MarshalJSON, naming a top-level todo element "todo"
+func (t Todo) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type todoFields Todo
+	if start.Name.Local == "Todo" {
+		start.Name.Local = "todo"
+	}
+	return e.EncodeElement(struct {
+		todoFields
+		TimeRemaining *int64 `xml:"time_remaining,omitempty"`
+	}{todoFields(t), t.timeRemaining()}, start)
+}
+
+// TodoInput is the body accepted by CreateTodo and UpdateTodo. It only
+// holds client-settable fields, so IDs and timestamps sent by clients are
+// ignored rather than silently overwritten. UpdateMask is only honoured by
+// UpdateTodo.
+type TodoInput struct {
+	Title       string     `json:"title" binding:"required"`
+	Description string     `json:"description"`
+	Completed   bool       `json:"completed"`
+	ParentID    *int       `json:"parent_id"`
+	Project     string     `json:"project" binding:"max=100"`
+	Progress    int        `json:"progress" binding:"min=0,max=100"`
+	DueDate     *time.Time `json:"due_date"`
+	Priority    string     `json:"priority" binding:"omitempty,oneof=low medium high"`
+	Tags        []string   `json:"tags"`
+	UpdateMask  []string   `json:"update_mask"`
+}
+
+// toTodo copies the input into a new todo without ID or timestamps
+func (in TodoInput) toTodo() Todo {
+	return Todo{
+		Title:       in.Title,
+		Description: in.Description,
+		Completed:   in.Completed,
+		ParentID:    in.ParentID,
+		Project:     in.Project,
+		Progress:    in.Progress,
+		DueDate:     in.DueDate,
+		Priority:    in.Priority,
+		Tags:        in.Tags,
+	}
+}
+
+// priorityRanks orders the accepted priority values; todos without a
+// priority rank lowest
+var priorityRanks = map[string]int{
+	"low":    1,
+	"medium": 2,
+	"high":   3,
+}
+
+// ProjectRequest is the body accepted when moving a todo to another
+// project. Leaving out project makes the request a no-op.
+type ProjectRequest struct {
+	Project *string `json:"project" binding:"omitempty,max=100"`
+}
+
+// ProgressRequest is the body accepted when updating a todo's progress.
+// Leaving out progress makes the request a no-op.
+type ProgressRequest struct {
+	Progress *int `json:"progress" binding:"omitempty,min=0,max=100"`
+}
+
+// Settings loaded from the environment at startup
+var (
+	// autoCompleteOnProgress marks todos completed when progress reaches
+	// 100 and clears completion when it drops below
+	autoCompleteOnProgress bool
+
+	// upsertOnPut makes UpdateTodo create missing todos by default
+	upsertOnPut bool
+
+	// strictJSON rejects create and update bodies with unknown fields or
+	// trailing data
+	strictJSON bool
+
+	// requireDescription rejects create and update bodies whose
+	// description is blank
+	requireDescription bool
+
+	// maxTags and maxTagLength limit the tags attached to a single todo
+	maxTags      = 20
+	maxTagLength = 50
+
+	// todoDefaults fill in the fields new todos leave out
+	todoDefaults TodoDefaults
+
+	// defaultPageSize and maxPageSize bound the list's limit parameter
+	defaultPageSize = 10
+	maxPageSize     = 100
+
+	// totalCountCap bounds how many matches the list counts before
+	// reporting an estimated total; 0 always counts exactly
+	totalCountCap int
+
+	// requireSubtasksComplete stops a todo from being completed while it
+	// has incomplete subtasks
+	requireSubtasksComplete bool
+
+	// lockCompleted rejects edits to completed todos, apart from marking
+	// them incomplete again
+	lockCompleted bool
+
+	// listKey is the key todo arrays are returned under in list responses
+	listKey = "todos"
+)
+
 // In-memory database
 var (
-	todos   []Todo
-	nextID  int = 1
-	todoMu  sync.RWMutex
+	todos  []Todo
+	nextID int = 1
+	todoMu sync.RWMutex
 )
 
+// maxTodoID bounds todo IDs, which are always below it, so moving nextID
+// past an ID can never overflow
+const maxTodoID = math.MaxInt32
+
+// errIDsExhausted is the message of responses to creates that would need
+// an ID at or above maxTodoID
+const errIDsExhausted = "Todo IDs exhausted"
+
+// idsAvailable reports whether n more todos can be given IDs from nextID.
+// Callers must hold todoMu.
+func idsAvailable(n int) bool {
+	return n <= maxTodoID-nextID
+}
+
+// seedTodos replaces the store with a copy of initial and moves nextID past
+// the highest ID in it, so state can be set up without going through the
+// handlers. It panics on an ID at or above maxTodoID. Callers must hold
+// todoMu.
+func seedTodos(initial []Todo) {
+	todos = slices.Clone(initial)
+	nextID = 1
+	for _, todo := range todos {
+		if todo.ID >= maxTodoID {
+			panic(fmt.Sprintf("seeded todo ID %d must be below %d", todo.ID, maxTodoID))
+		}
+		nextID = max(nextID, todo.ID+1)
+	}
+	invalidateListCache()
+	resetChangeLog()
+}
+
+// parseTodoID parses the :id route parameter, writing a 400 response and
+// returning false when it is not a positive integer
+func parseTodoID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondJSON(c, http.StatusBadRequest, gin.H{"error": "Invalid todo ID"})
+		return 0, false
+	}
+	if id <= 0 {
+		respondJSON(c, http.StatusBadRequest, gin.H{"error": "todo ID must be a positive integer"})
+		return 0, false
+	}
+	return id, true
+}
+
+// isDryRun reports whether the request asked to validate and preview a
+// mutation without applying it
+func isDryRun(c *gin.Context) bool {
+	return c.Query("dry_run") == "true"
+}
+
+// isUpsert reports whether a PUT to a missing todo should create it, using
+// the upsert query parameter when given and the configured default otherwise
+func isUpsert(c *gin.Context) bool {
+	if param, ok := c.GetQuery("upsert"); ok {
+		if upsert, err := strconv.ParseBool(param); err == nil {
+			return upsert
+		}
+	}
+	return upsertOnPut
+}
+
+// findTodoIndex returns the index of the todo with the given ID, or -1.
+// Callers must hold todoMu.
+func findTodoIndex(id int) int {
+	for i, todo := range todos {
+		if todo.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// createsCycle reports whether making parentID the parent of id would
+// introduce a cycle in the todo tree. Callers must hold todoMu.
+func createsCycle(id, parentID int) bool {
+	for current := parentID; ; {
+		if current == id {
+			return true
+		}
+		i := findTodoIndex(current)
+		if i == -1 || todos[i].ParentID == nil {
+			return false
+		}
+		current = *todos[i].ParentID
+	}
+}
+
+// applyProgress syncs the completed flag with progress when auto-complete
+// is enabled
+func applyProgress(todo *Todo) {
+	if !autoCompleteOnProgress {
+		return
+	}
+	todo.Completed = todo.Progress == 100
+}
+
+// trackCompletion keeps CompletedAt in step with the completed flag: it is
+// set to now when the todo has just been completed and cleared while the
+// todo is not completed
+func trackCompletion(todo *Todo, wasCompleted bool, now time.Time) {
+	switch {
+	case !todo.Completed:
+		todo.CompletedAt = nil
+	case !wasCompleted || todo.CompletedAt == nil:
+		todo.CompletedAt = &now
+	}
+}
+
+// changedFields returns the JSON names of the client-settable fields that
+// differ between before and after
+func changedFields(before, after Todo) []string {
+	changed := []string{}
+	if before.Title != after.Title {
+		changed = append(changed, "title")
+	}
+	if before.Description != after.Description {
+		changed = append(changed, "description")
+	}
+	if before.Completed != after.Completed {
+		changed = append(changed, "completed")
+	}
+	if !equalOptional(before.ParentID, after.ParentID, func(a, b int) bool { return a == b }) {
+		changed = append(changed, "parent_id")
+	}
+	if before.Project != after.Project {
+		changed = append(changed, "project")
+	}
+	if before.Progress != after.Progress {
+		changed = append(changed, "progress")
+	}
+	if !equalOptional(before.DueDate, after.DueDate, time.Time.Equal) {
+		changed = append(changed, "due_date")
+	}
+	if before.Priority != after.Priority {
+		changed = append(changed, "priority")
+	}
+	if !slices.Equal(before.Tags, after.Tags) {
+		changed = append(changed, "tags")
+	}
+	return changed
+}
+
+// fieldValues reads each client-settable field, keyed by its JSON name
+var fieldValues = map[string]func(Todo) any{
+	"title":       func(t Todo) any { return t.Title },
+	"description": func(t Todo) any { return t.Description },
+	"completed":   func(t Todo) any { return t.Completed },
+	"parent_id":   func(t Todo) any { return t.ParentID },
+	"project":     func(t Todo) any { return t.Project },
+	"progress":    func(t Todo) any { return t.Progress },
+	"due_date":    func(t Todo) any { return t.DueDate },
+	"priority":    func(t Todo) any { return t.Priority },
+	"tags":        func(t Todo) any { return t.Tags },
+}
+
+// respondUpdated answers an update that moved a todo from before to after
+// with after, or with a changed_only=true query parameter with just its
+// ID, updated_at and the fields that changed. Cleared fields are null.
+func respondUpdated(c *gin.Context, before, after Todo) {
+	if c.Query("changed_only") != "true" {
+		respondJSON(c, http.StatusOK, after)
+		return
+	}
+
+	response := gin.H{"id": after.ID, "updated_at": after.UpdatedAt}
+	for _, field := range changedFields(before, after) {
+		response[field] = fieldValues[field](after)
+	}
+	if before.Completed != after.Completed {
+		response["completed_at"] = after.CompletedAt
+	}
+	respondJSON(c, http.StatusOK, response)
+}
+
+// equalOptional compares two optional values, treating two nils as equal
+func equalOptional[T any](a, b *T, equal func(T, T) bool) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return equal(*a, *b)
+}
+
+// normalizeTags trims, lowercases and deduplicates tags, preserving their
+// first-seen order, and enforces the configured tag limits
+func normalizeTags(tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+
+	normalized := []string{}
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > maxTagLength {
+			return nil, fmt.Errorf("tag %q exceeds the maximum length of %d characters", tag, maxTagLength)
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	if len(normalized) > maxTags {
+		return nil, fmt.Errorf("too many tags: at most %d allowed", maxTags)
+	}
+	return normalized, nil
+}
+
+// descendantIDs returns the IDs of all todos below id in the tree.
+// Callers must hold todoMu.
+func descendantIDs(id int) map[int]bool {
+	found := map[int]bool{}
+	queue := []int{id}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, todo := range todos {
+			if todo.ParentID != nil && *todo.ParentID == current && !found[todo.ID] {
+				found[todo.ID] = true
+				queue = append(queue, todo.ID)
+			}
+		}
+	}
+	return found
+}
+
+// hasPendingSubtasks reports whether any todo below id is incomplete. The
+// todos in toggled are treated as having their completed flag flipped, so a
+// batch can be checked before it is applied. Callers must hold todoMu.
+func hasPendingSubtasks(id int, toggled map[int]bool) bool {
+	descendants := descendantIDs(id)
+	for _, todo := range todos {
+		if descendants[todo.ID] && todo.Completed == toggled[todo.ID] {
+			return true
+		}
+	}
+	return false
+}
+
+// blocksCompletion reports whether moving a todo from before to after
+// completes it while requireSubtasksComplete forbids that. Callers must
+// hold todoMu.
+func blocksCompletion(before, after Todo) bool {
+	return requireSubtasksComplete && !before.Completed && after.Completed && hasPendingSubtasks(before.ID, nil)
+}
+
+// completedLockedMessage answers an edit that lockCompleted forbids
+const completedLockedMessage = "Todo is completed and locked; mark it incomplete to edit it"
+
+// editsLocked reports whether moving a todo from before to after edits a
+// completed todo while lockCompleted forbids that. Marking it incomplete is
+// allowed as long as nothing else changes with it.
+func editsLocked(before, after Todo) bool {
+	if !lockCompleted || !before.Completed {
+		return false
+	}
+	changed := changedFields(before, after)
+	return len(changed) > 0 && !slices.Equal(changed, []string{"completed"})
+}
+
+// bindTodoInput decodes a create or update body into input and runs its
+// binding rules, plus the rules that depend on configuration. With
+// strictJSON set, fields TodoInput does not declare and data after the
+// object are rejected rather than ignored.
+func bindTodoInput(c *gin.Context, input *TodoInput) error {
+	if err := decodeTodoInput(c, input); err != nil {
+		return err
+	}
+	return checkText(*input)
+}
+
+// checkText enforces the configured title and description rules on input
+func checkText(input TodoInput) error {
+	if err := checkLength("title", input.Title, maxTitleLength); err != nil {
+		return err
+	}
+	if err := checkLength("description", input.Description, maxDescriptionLength); err != nil {
+		return err
+	}
+	return checkDescription(input)
+}
+
+// checkDescription enforces requireDescription on input
+func checkDescription(input TodoInput) error {
+	if requireDescription && strings.TrimSpace(input.Description) == "" {
+		return &fieldError{field: "description", err: errors.New("description is required")}
+	}
+	return nil
+}
+
+// errEmptyBody reports a request sent without the body it requires
+var errEmptyBody = errors.New("request body is required")
+
+// requireBody returns errEmptyBody when the request body is missing or
+// blank, so it can be told apart from malformed JSON. The body stays
+// readable otherwise.
+func requireBody(c *gin.Context) error {
+	if c.Request.Body == nil {
+		return errEmptyBody
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return errEmptyBody
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	return nil
+}
+
+// errTrailingData reports a body with more than one JSON value
+var errTrailingData = errors.New("request body must contain a single JSON object")
+
+// decodeTodoInput decodes and validates the body against TodoInput's
+// binding tags. With strictJSON set, unknown fields and anything after the
+// object are rejected.
+func decodeTodoInput(c *gin.Context, input *TodoInput) error {
+	if err := requireBody(c); err != nil {
+		return err
+	}
+	if !strictJSON {
+		return c.ShouldBindJSON(input)
+	}
+	decoder := json.NewDecoder(c.Request.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(input); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return errTrailingData
+	}
+	return binding.Validator.ValidateStruct(input)
+}
+
+// bindNewTodo decodes and validates a create body into a todo without ID
+// or timestamps
+func bindNewTodo(c *gin.Context) (Todo, error) {
+	var input TodoInput
+	if err := bindTodoInput(c, &input); err != nil {
+		return Todo{}, err
+	}
+	newTodo := input.toTodo()
+	todoDefaults.applyTo(&newTodo)
+
+	var err error
+	if newTodo.Tags, err = normalizeTags(newTodo.Tags); err != nil {
+		return Todo{}, &fieldError{field: "tags", err: err}
+	}
+	return newTodo, nil
+}
+
+// checkNewTodo validates the parts of a new todo that depend on the stored
+// todos. Callers must hold todoMu.
+func checkNewTodo(newTodo Todo) error {
+	if newTodo.ParentID != nil && findTodoIndex(*newTodo.ParentID) == -1 {
+		return &fieldError{field: "parent_id", err: errors.New("Parent todo not found")}
+	}
+	return nil
+}
+
 // CreateTodo creates a new todo
 func CreateTodo(c *gin.Context) {
-	var newTodo Todo
-	if err := c.ShouldBindJSON(&newTodo); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	newTodo, err := bindNewTodo(c)
+	if err != nil {
+		respondJSON(c, bindErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	todoMu.Lock()
 	defer todoMu.Unlock()
 
+	if err := checkNewTodo(newTodo); err != nil {
+		respondJSON(c, bindErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	// Point at the new resource relative to the collection path so any
+	// base path the request came through is preserved
+	storeNewTodo(c, newTodo, strings.TrimSuffix(c.Request.URL.Path, "/"))
+}
+
+// storeNewTodo assigns newTodo an ID and timestamps and stores it, or only
+// previews it on a dry run. The Location header points below
+// collectionPath. Callers must hold todoMu.
+func storeNewTodo(c *gin.Context, newTodo Todo, collectionPath string) {
+	if !idsAvailable(1) {
+		respondJSON(c, http.StatusInsufficientStorage, gin.H{"error": errIDsExhausted})
+		return
+	}
+	applyProgress(&newTodo)
 	newTodo.ID = nextID
-	nextID++
 	newTodo.CreatedAt = time.Now()
-	newTodo.UpdatedAt = time.Now()
+	newTodo.UpdatedAt = newTodo.CreatedAt
+	trackCompletion(&newTodo, false, newTodo.CreatedAt)
+
+	if isDryRun(c) {
+		respondJSON(c, http.StatusOK, gin.H{"dry_run": true, "todo": newTodo})
+		return
+	}
+
+	nextID++
 	todos = append(todos, newTodo)
+	invalidateListCache()
+	publishChange(eventTodoCreated, newTodo)
 
-	c.JSON(http.StatusCreated, newTodo)
+	c.Header("Location", fmt.Sprintf("%s/%d", collectionPath, newTodo.ID))
+	respondJSON(c, http.StatusCreated, newTodo)
 }
 
-// GetTodos returns todos with pagination support
-func GetTodos(c *gin.Context) {
-	todoMu.RLock()
-	defer todoMu.RUnlock()
+// FindOrCreateTodo returns the todo whose title matches the body's exactly,
+// creating it from the body when there is none
+func FindOrCreateTodo(c *gin.Context) {
+	newTodo, err := bindNewTodo(c)
+	if err != nil {
+		respondJSON(c, bindErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	if strings.TrimSpace(newTodo.Title) == "" {
+		respondJSON(c, validationStatus(), gin.H{"error": "title is required"})
+		return
+	}
 
-	// Parse pagination parameters
-	page := 1
-	limit := 10
+	todoMu.Lock()
+	defer todoMu.Unlock()
 
-	if pageParam := c.Query("page"); pageParam != "" {
-		if p, err := strconv.Atoi(pageParam); err == nil && p > 0 {
-			page = p
+	for _, todo := range todos {
+		if todo.Title == newTodo.Title {
+			respondJSON(c, http.StatusOK, todo)
+			return
 		}
 	}
 
-	if limitParam := c.Query("limit"); limitParam != "" {
-		if l, err := strconv.Atoi(limitParam); err == nil && l > 0 {
-			if l <= 100 {
-				limit = l
-			} else {
-				limit = 100 // Cap at maximum
-			}
+	if err := checkNewTodo(newTodo); err != nil {
+		respondJSON(c, bindErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	storeNewTodo(c, newTodo, path.Dir(c.Request.URL.Path))
+}
+
+// presenceFilters are the query parameters selecting todos by whether an
+// optional field is set, as has_due_date=false does for todos without one
+var presenceFilters = []struct {
+	param string
+	isSet func(Todo) bool
+}{
+	{"has_due_date", func(todo Todo) bool { return todo.DueDate != nil }},
+	{"has_tags", func(todo Todo) bool { return len(todo.Tags) > 0 }},
+	{"has_project", func(todo Todo) bool { return todo.Project != "" }},
+	{"has_priority", func(todo Todo) bool { return todo.Priority != "" }},
+}
+
+// todoMatcher returns a predicate for the filter query parameters, or nil
+// when no filter is applied. Invalid filter values are reported as errors.
+func todoMatcher(c *gin.Context) (func(Todo) bool, error) {
+	var predicates []func(Todo) bool
+
+	if project, ok := c.GetQuery("project"); ok {
+		predicates = append(predicates, func(todo Todo) bool {
+			return todo.Project == project
+		})
+	}
+
+	if pattern := searchPattern(c); pattern != nil {
+		predicates = append(predicates, func(todo Todo) bool {
+			return searchMatches(todo, pattern)
+		})
+	}
+
+	if completedParam := c.Query("completed"); completedParam != "" {
+		completed, err := strconv.ParseBool(completedParam)
+		if err != nil {
+			return nil, fmt.Errorf("invalid completed filter %q: must be true or false", completedParam)
 		}
+		predicates = append(predicates, func(todo Todo) bool {
+			return todo.Completed == completed
+		})
 	}
 
-	// Calculate pagination
-	totalCount := len(todos)
-	totalPages := (totalCount + limit - 1) / limit
-	if totalPages == 0 {
-		totalPages = 1
+	if priorityParam := c.Query("priority"); priorityParam != "" {
+		// Multiple comma-separated priorities match any of them
+		priorities := map[string]bool{}
+		for _, priority := range strings.Split(priorityParam, ",") {
+			priority = strings.TrimSpace(priority)
+			if _, ok := priorityRanks[priority]; !ok {
+				return nil, fmt.Errorf("invalid priority filter %q: must be low, medium or high", priority)
+			}
+			priorities[priority] = true
+		}
+		predicates = append(predicates, func(todo Todo) bool {
+			return priorities[todo.Priority]
+		})
 	}
 
-	// Calculate offset
-	offset := (page - 1) * limit
-	if offset >= totalCount {
-		// Page is beyond available data
-		c.JSON(http.StatusOK, gin.H{
-			"todos":        []Todo{},
-			"total_count":  totalCount,
-			"current_page": page,
-			"total_pages":  totalPages,
-			"per_page":     limit,
-			"has_next":     false,
-			"has_prev":     page > 1,
+	for _, filter := range presenceFilters {
+		param := c.Query(filter.param)
+		if param == "" {
+			continue
+		}
+		want, err := strconv.ParseBool(param)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s filter %q: must be true or false", filter.param, param)
+		}
+		isSet := filter.isSet
+		predicates = append(predicates, func(todo Todo) bool {
+			return isSet(todo) == want
 		})
+	}
+
+	if dueParam := c.Query("due"); dueParam != "" {
+		now, err := requestNow(c)
+		if err != nil {
+			return nil, err
+		}
+		matchesDue, err := dueMatcher(dueParam, now)
+		if err != nil {
+			return nil, err
+		}
+		predicates = append(predicates, matchesDue)
+	}
+
+	if len(predicates) == 0 {
+		return nil, nil
+	}
+	return func(todo Todo) bool {
+		for _, predicate := range predicates {
+			if !predicate(todo) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// filterTodos returns the todos accepted by matches, or every todo when
+// matches is nil. Callers must hold todoMu.
+func filterTodos(matches func(Todo) bool) []Todo {
+	if matches == nil {
+		return todos
+	}
+
+	filtered := []Todo{}
+	for _, todo := range todos {
+		if matches(todo) {
+			filtered = append(filtered, todo)
+		}
+	}
+	return filtered
+}
+
+// GetTodos returns todos with pagination and filtering support
+func GetTodos(c *gin.Context) {
+	matches, err := todoMatcher(c)
+	if err != nil {
+		respondJSON(c, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sortSpec := defaultSort
+	if sortParam := c.Query("sort"); sortParam != "" {
+		if sortSpec, err = parseSort(sortParam); err != nil {
+			respondJSON(c, http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if collectionNotModified(c) {
+		return
+	}
+
+	if wantsBareList(c) {
+		todoMu.RLock()
+		response := listTodos(c, matches, sortSpec)
+		todoMu.RUnlock()
+
+		respondBareList(c, response)
+		return
+	}
+
+	if listResponseCache == nil {
+		todoMu.RLock()
+		defer todoMu.RUnlock()
+
+		respondJSON(c, http.StatusOK, listTodos(c, matches, sortSpec))
 		return
 	}
 
-	// Get the slice for this page
+	key := c.Request.URL.RawQuery + "|" + c.GetHeader("X-Page-Size")
+	if acceptsProfile(c, linksProfile) {
+		key = "links:" + key
+	}
+	if wantsEnvelope(c) {
+		key = "envelope:" + key
+	}
+	if wantsXML(c) {
+		key = "xml:" + key
+	}
+
+	todoMu.RLock()
+	body, ok := listResponseCache.get(key)
+	generation := listResponseCache.generation
+	var response gin.H
+	if !ok {
+		response = listTodos(c, matches, sortSpec)
+	}
+	todoMu.RUnlock()
+
+	if !ok {
+		if body, err = marshalResponse(c, response); err != nil {
+			respondJSON(c, http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if !hasDueDates(response) {
+			todoMu.Lock()
+			listResponseCache.put(key, generation, body)
+			todoMu.Unlock()
+		}
+	}
+
+	c.Data(http.StatusOK, responseContentType(c), body)
+}
+
+// HeadTodos answers HEAD on the list with the headers GetTodos would send,
+// such as ETag and Content-Type, plus the number of todos matching the list
+// filters in X-Total-Count, without a body
+func HeadTodos(c *gin.Context) {
+	if matches, err := todoMatcher(c); err == nil {
+		todoMu.RLock()
+		total := len(filterTodos(matches))
+		todoMu.RUnlock()
+		c.Header("X-Total-Count", strconv.Itoa(total))
+	}
+	headOf(GetTodos)(c)
+}
+
+// listTodos builds the paginated list response for the todos accepted by
+// matches, ordered by sortSpec when it is non-nil. A search adds the match
+// count, and highlights the matches when asked to; _links are added to
+// each item when the request asks for them. Callers must hold todoMu.
+func listTodos(c *gin.Context, matches func(Todo) bool, sortSpec *todoSort) gin.H {
+	response := listTodoPage(c, matches, sortSpec)
+	if pattern := searchPattern(c); pattern != nil {
+		response["search_matched"] = response["total_count"]
+		if c.Query("highlight") == "true" {
+			response[listKey] = highlightTodos(response[listKey].([]Todo), pattern)
+		}
+	}
+	if wantsLinks(c) {
+		collectionPath := strings.TrimSuffix(c.Request.URL.Path, "/")
+		response[listKey] = withLinks(response[listKey].([]Todo), collectionPath)
+	}
+	return response
+}
+
+// listTodoPage builds the paginated list response without links. Callers
+// must hold todoMu.
+func listTodoPage(c *gin.Context, matches func(Todo) bool, sortSpec *todoSort) gin.H {
+	page, limit := pageParams(c)
+
+	if totalCountCap > 0 && sortSpec == nil {
+		return listTodosCapped(matches, page, limit)
+	}
+
+	if sortSpec != nil {
+		return paginate(sortTodos(filterTodos(matches), sortSpec), page, limit).response(listKey)
+	}
+	items, total := pageOfMatches(matches, (page-1)*limit, limit)
+	return newPage(items, total, page, limit).response(listKey)
+}
+
+// pageOfMatches returns the todos accepted by matches that fall in the
+// window starting at offset, along with the total number of matches. It
+// counts and collects in a single pass instead of building the full
+// filtered slice. Callers must hold todoMu.
+func pageOfMatches(matches func(Todo) bool, offset, limit int) ([]Todo, int) {
+	page := []Todo{}
+	total := 0
+	for _, todo := range todos {
+		if matches != nil && !matches(todo) {
+			continue
+		}
+		if total >= offset && total < offset+limit {
+			page = append(page, todo)
+		}
+		total++
+	}
+	return page, total
+}
+
+// listTodosCapped builds the list response without a full scan: matching
+// stops once the requested page is collected and more than totalCountCap
+// matches were seen, in which case total_count is reported as the cap and
+// flagged as an estimate. Callers must hold todoMu.
+func listTodosCapped(matches func(Todo) bool, page, limit int) gin.H {
+	offset := (page - 1) * limit
 	end := offset + limit
-	if end > totalCount {
-		end = totalCount
+
+	paginatedTodos := []Todo{}
+	matched := 0
+	for _, todo := range todos {
+		if matches != nil && !matches(todo) {
+			continue
+		}
+		if matched >= offset && matched < end {
+			paginatedTodos = append(paginatedTodos, todo)
+		}
+		matched++
+		if matched > totalCountCap && matched > end {
+			break
+		}
 	}
 
-	paginatedTodos := todos[offset:end]
+	totalCount := matched
+	isEstimate := matched > totalCountCap
+	if isEstimate {
+		totalCount = totalCountCap
+	}
+	totalPages := (totalCount + limit - 1) / limit
+	if totalPages == 0 {
+		totalPages = 1
+	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"todos":        paginatedTodos,
-		"total_count":  totalCount,
-		"current_page": page,
-		"total_pages":  totalPages,
-		"per_page":     limit,
-		"has_next":     page < totalPages,
-		"has_prev":     page > 1,
-	})
+	return gin.H{
+		listKey:             paginatedTodos,
+		"total_count":       totalCount,
+		"total_is_estimate": isEstimate,
+		"current_page":      page,
+		"total_pages":       totalPages,
+		"per_page":          limit,
+		"has_next":          matched > end,
+		"has_prev":          page > 1,
+	}
 }
 
 // GetTodo returns a specific todo by ID
 func GetTodo(c *gin.Context) {
-	id, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid todo ID"})
+	id, ok := parseTodoID(c)
+	if !ok {
 		return
 	}
 
@@ -126,102 +930,498 @@ func GetTodo(c *gin.Context) {
 
 	for _, todo := range todos {
 		if todo.ID == id {
-			c.JSON(http.StatusOK, todo)
+			respondJSON(c, http.StatusOK, todo)
 			return
 		}
 	}
 
-	c.JSON(http.StatusNotFound, gin.H{"error": "Todo not found"})
+	respondJSON(c, http.StatusNotFound, gin.H{"error": "Todo not found"})
+}
+
+// replaceTodo replaces the client-settable fields of todos[i] with those
+// of updatedTodo and responds with the result. Nothing is stored, and
+// updated_at is left alone, when no field changes. Callers must hold
+// todoMu.
+func replaceTodo(c *gin.Context, i int, updatedTodo Todo) {
+	todo := todos[i]
+	updatedTodo.ID = todo.ID
+	updatedTodo.CreatedAt = todo.CreatedAt
+	updatedTodo.Comments = todo.Comments
+	updatedTodo.CompletedAt = todo.CompletedAt
+	applyProgress(&updatedTodo)
+	trackCompletion(&updatedTodo, todo.Completed, time.Now())
+	if blocksCompletion(todo, updatedTodo) {
+		respondJSON(c, http.StatusConflict, gin.H{"error": "Todo has incomplete subtasks"})
+		return
+	}
+	if editsLocked(todo, updatedTodo) {
+		respondJSON(c, http.StatusConflict, gin.H{"error": completedLockedMessage})
+		return
+	}
+
+	// Only bump UpdatedAt when something actually changed so
+	// "modified since" syncs skip no-op updates
+	changed := len(changedFields(todo, updatedTodo)) > 0
+	if changed {
+		updatedTodo.UpdatedAt = time.Now()
+	} else {
+		updatedTodo = todo
+	}
+
+	if isDryRun(c) {
+		respondJSON(c, http.StatusOK, gin.H{"dry_run": true, "todo": updatedTodo})
+		return
+	}
+	if changed {
+		todos[i] = updatedTodo
+		invalidateListCache()
+		publishChange(eventTodoUpdated, updatedTodo)
+	}
+	respondUpdated(c, todo, updatedTodo)
 }
 
 // UpdateTodo updates an existing todo
 func UpdateTodo(c *gin.Context) {
-	id, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid todo ID"})
+	id, ok := parseTodoID(c)
+	if !ok {
 		return
 	}
 
-	var updatedTodo Todo
-	if err := c.ShouldBindJSON(&updatedTodo); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var input TodoInput
+	bindErr := decodeTodoInput(c, &input)
+	var ruleErrs validator.ValidationErrors
+	if bindErr != nil && !errors.As(bindErr, &ruleErrs) {
+		respondJSON(c, bindErrorStatus(bindErr), gin.H{"error": bindErr.Error()})
 		return
 	}
+	// With an update mask, fields outside it are ignored, so only the
+	// masked ones are checked
+	mask, err := parseUpdateMask(c, input.UpdateMask)
+	if err != nil {
+		respondJSON(c, validationStatus(), gin.H{"error": err.Error()})
+		return
+	}
+	if err := ruleErrorsInMask(ruleErrs, mask); err != nil {
+		respondJSON(c, bindErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	masked := func(field string) bool { return mask == nil || mask[field] }
+	if masked("title") {
+		if err := checkLength("title", input.Title, maxTitleLength); err != nil {
+			respondJSON(c, validationStatus(), gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if masked("description") {
+		err := checkLength("description", input.Description, maxDescriptionLength)
+		if err == nil {
+			err = checkDescription(input)
+		}
+		if err != nil {
+			respondJSON(c, validationStatus(), gin.H{"error": err.Error()})
+			return
+		}
+	}
+	updatedTodo := input.toTodo()
+
+	if masked("tags") {
+		if updatedTodo.Tags, err = normalizeTags(updatedTodo.Tags); err != nil {
+			respondJSON(c, validationStatus(), gin.H{"error": err.Error()})
+			return
+		}
+	}
 
 	todoMu.Lock()
 	defer todoMu.Unlock()
 
-	for i, todo := range todos {
-		if todo.ID == id {
-			updatedTodo.ID = id
-			updatedTodo.CreatedAt = todo.CreatedAt
-			updatedTodo.UpdatedAt = time.Now()
-			todos[i] = updatedTodo
-			c.JSON(http.StatusOK, updatedTodo)
+	if updatedTodo.ParentID != nil && masked("parent_id") {
+		if findTodoIndex(*updatedTodo.ParentID) == -1 {
+			respondJSON(c, validationStatus(), gin.H{"error": "Parent todo not found"})
+			return
+		}
+		if createsCycle(id, *updatedTodo.ParentID) {
+			respondJSON(c, validationStatus(), gin.H{"error": "Parent would create a cycle"})
 			return
 		}
 	}
 
-	c.JSON(http.StatusNotFound, gin.H{"error": "Todo not found"})
+	if i := findTodoIndex(id); i != -1 {
+		if mask != nil {
+			updatedTodo = applyUpdateMask(todos[i], updatedTodo, mask)
+		}
+		replaceTodo(c, i, updatedTodo)
+		return
+	}
+
+	if !isUpsert(c) {
+		respondJSON(c, http.StatusNotFound, gin.H{"error": "Todo not found"})
+		return
+	}
+
+	// Create the todo under the requested ID, moving nextID past it so
+	// later creates cannot collide
+	if id >= maxTodoID {
+		respondJSON(c, http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Todo ID must be below %d to be created", maxTodoID)})
+		return
+	}
+	if mask != nil {
+		updatedTodo = applyUpdateMask(Todo{}, updatedTodo, mask)
+	}
+	updatedTodo.ID = id
+	updatedTodo.CreatedAt = time.Now()
+	updatedTodo.UpdatedAt = updatedTodo.CreatedAt
+	applyProgress(&updatedTodo)
+	trackCompletion(&updatedTodo, false, updatedTodo.CreatedAt)
+
+	if isDryRun(c) {
+		respondJSON(c, http.StatusOK, gin.H{"dry_run": true, "todo": updatedTodo})
+		return
+	}
+
+	if id >= nextID {
+		nextID = id + 1
+	}
+	todos = append(todos, updatedTodo)
+	invalidateListCache()
+	publishChange(eventTodoCreated, updatedTodo)
+
+	c.Header("Location", c.Request.URL.Path)
+	respondJSON(c, http.StatusCreated, updatedTodo)
 }
 
 // DeleteTodo deletes a todo by ID
 func DeleteTodo(c *gin.Context) {
-	id, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid todo ID"})
+	id, ok := parseTodoID(c)
+	if !ok {
 		return
 	}
 
+	cascade := c.Query("cascade") == "true"
+
 	todoMu.Lock()
 	defer todoMu.Unlock()
 
-	for i, todo := range todos {
-		if todo.ID == id {
-			todos = append(todos[:i], todos[i+1:]...)
-			c.JSON(http.StatusOK, gin.H{"message": "Todo deleted successfully"})
-			return
+	if findTodoIndex(id) == -1 {
+		respondJSON(c, http.StatusNotFound, gin.H{"error": "Todo not found"})
+		return
+	}
+
+	descendants := descendantIDs(id)
+	if len(descendants) > 0 && !cascade {
+		respondJSON(c, http.StatusConflict, gin.H{"error": "Todo has children; use cascade=true to delete them"})
+		return
+	}
+
+	if isDryRun(c) {
+		deletedIDs := []int{id}
+		for _, todo := range todos {
+			if descendants[todo.ID] {
+				deletedIDs = append(deletedIDs, todo.ID)
+			}
 		}
+		respondJSON(c, http.StatusOK, gin.H{"dry_run": true, "deleted_ids": deletedIDs})
+		return
 	}
 
-	c.JSON(http.StatusNotFound, gin.H{"error": "Todo not found"})
+	// Remove the todo and, when cascading, its whole subtree
+	remaining := todos[:0]
+	for _, todo := range todos {
+		if todo.ID != id && !descendants[todo.ID] {
+			remaining = append(remaining, todo)
+		} else {
+			publishChange(eventTodoDeleted, todo)
+		}
+	}
+	todos = remaining
+	invalidateListCache()
+
+	respondJSON(c, http.StatusOK, gin.H{"message": "Todo deleted successfully"})
 }
 
-func main() {
-	// Initialize Gin router
-	r := gin.Default()
-
-	// CORS middleware
-	r.Use(func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
+// MoveTodoProject moves a todo to another project
+func MoveTodoProject(c *gin.Context) {
+	id, ok := parseTodoID(c)
+	if !ok {
+		return
+	}
+
+	var req ProjectRequest
+	if err := requireBody(c); err != nil {
+		respondJSON(c, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondJSON(c, bindErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	todoMu.Lock()
+	defer todoMu.Unlock()
+
+	i := findTodoIndex(id)
+	if i == -1 {
+		respondJSON(c, http.StatusNotFound, gin.H{"error": "Todo not found"})
+		return
+	}
+	if req.Project == nil {
+		respondUpdated(c, todos[i], todos[i])
+		return
+	}
+
+	updated := todos[i]
+	updated.Project = *req.Project
+	if editsLocked(todos[i], updated) {
+		respondJSON(c, http.StatusConflict, gin.H{"error": completedLockedMessage})
+		return
+	}
+
+	// Moving a todo to the project it is already in changes nothing
+	changed := len(changedFields(todos[i], updated)) > 0
+	if changed {
+		updated.UpdatedAt = time.Now()
+	}
+	if isDryRun(c) {
+		respondJSON(c, http.StatusOK, gin.H{"dry_run": true, "todo": updated})
+		return
+	}
+	before := todos[i]
+	if changed {
+		todos[i] = updated
+		invalidateListCache()
+		publishChange(eventTodoUpdated, todos[i])
+	}
+
+	respondUpdated(c, before, todos[i])
+}
+
+// UpdateTodoProgress updates the progress of a todo
+func UpdateTodoProgress(c *gin.Context) {
+	id, ok := parseTodoID(c)
+	if !ok {
+		return
+	}
+
+	var req ProgressRequest
+	if err := requireBody(c); err != nil {
+		respondJSON(c, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondJSON(c, bindErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	todoMu.Lock()
+	defer todoMu.Unlock()
+
+	i := findTodoIndex(id)
+	if i == -1 {
+		respondJSON(c, http.StatusNotFound, gin.H{"error": "Todo not found"})
+		return
+	}
+	if req.Progress == nil {
+		respondUpdated(c, todos[i], todos[i])
+		return
+	}
+
+	updated := todos[i]
+	updated.Progress = *req.Progress
+	updated.UpdatedAt = time.Now()
+	applyProgress(&updated)
+	trackCompletion(&updated, todos[i].Completed, updated.UpdatedAt)
+	if blocksCompletion(todos[i], updated) {
+		respondJSON(c, http.StatusConflict, gin.H{"error": "Todo has incomplete subtasks"})
+		return
+	}
+	if editsLocked(todos[i], updated) {
+		respondJSON(c, http.StatusConflict, gin.H{"error": completedLockedMessage})
+		return
+	}
+	if isDryRun(c) {
+		respondJSON(c, http.StatusOK, gin.H{"dry_run": true, "todo": updated})
+		return
+	}
+	before := todos[i]
+	todos[i] = updated
+	invalidateListCache()
+	publishChange(eventTodoUpdated, todos[i])
+
+	respondUpdated(c, before, todos[i])
+}
+
+// GetTodoChildren returns the direct children of a todo
+func GetTodoChildren(c *gin.Context) {
+	id, ok := parseTodoID(c)
+	if !ok {
+		return
+	}
+
+	todoMu.RLock()
+	defer todoMu.RUnlock()
+
+	if findTodoIndex(id) == -1 {
+		respondJSON(c, http.StatusNotFound, gin.H{"error": "Todo not found"})
+		return
+	}
+
+	children := []Todo{}
+	for _, todo := range todos {
+		if todo.ParentID != nil && *todo.ParentID == id {
+			children = append(children, todo)
 		}
-		c.Next()
-	})
+	}
+
+	respondJSON(c, http.StatusOK, gin.H{listKey: children})
+}
+
+// newRouter builds the Gin engine with the middleware and routes enabled
+// by cfg
+func newRouter(cfg Config) *gin.Engine {
+	// Initialize Gin router
+	r := gin.New()
+	r.Use(assignRequestID(cfg.RequestIDHeader), gin.LoggerWithFormatter(logLine), gin.Recovery())
+	r.Use(trackInFlight())
+
+	// Warn about requests slower than the configured threshold
+	if cfg.SlowRequestThreshold > 0 {
+		r.Use(logSlowRequests(cfg.SlowRequestThreshold))
+	}
+
+	// CORS middleware, unless a gateway in front handles CORS
+	if cfg.CORSEnabled {
+		r.Use(cors(cfg.CORSAllowedOrigins, cfg.CORSMaxAge, cfg.CORSExposeHeaders, cfg.RequestIDHeader))
+	}
+
+	// Shed or queue requests beyond the configured concurrency limit
+	if cfg.MaxConcurrentRequests > 0 {
+		r.Use(limitConcurrency(cfg.MaxConcurrentRequests, cfg.ConcurrencyMode, cfg.ConcurrencyQueueWait))
+	}
+
+	// Gzip responses once they reach the configured size
+	if cfg.GzipEnabled {
+		r.Use(compressResponses(cfg.GzipMinSize))
+	}
+
+	// Answer 503 when a request takes longer than the configured timeout
+	if cfg.RequestTimeout > 0 {
+		r.Use(requestTimeout(cfg.RequestTimeout, "/api/v1/todos/export"))
+	}
+
+	// Reject oversized and deeply nested bodies before they are decoded
+	r.Use(limitJSONDepth(cfg.MaxJSONDepth, cfg.MaxBodyBytes))
+
+	// Collapse identical rapid writes when a deduplication window is set
+	if cfg.DedupWindow > 0 {
+		r.Use(newRequestDeduper(cfg.DedupWindow).Middleware())
+	}
+
+	// Run writes one at a time from a bounded queue when one is configured
+	if cfg.WriteQueueSize > 0 {
+		r.Use(queueWrites(cfg.WriteQueueSize))
+	}
 
 	// Routes
 	v1 := r.Group("/api/v1")
 	{
+		v1.GET("/features", featuresHandler(cfg))
+		v1.GET("/time", GetServerTime)
+		v1.GET("/schema/todo", GetTodoSchema)
 		v1.POST("/todos", CreateTodo)
+		v1.POST("/todos/validate", ValidateTodo)
+		v1.POST("/todos/find-or-create", FindOrCreateTodo)
+		v1.POST("/todos/batch-get", BatchGetTodos)
+		v1.POST("/todos/status", GetTodoStatuses)
+		v1.POST("/todos/toggle", ToggleTodos)
+		v1.POST("/todos/tag", TagTodos)
+		v1.POST("/todos/clone", CloneTodos)
+		v1.POST("/todos/import-url", ImportTodosFromURL)
 		v1.GET("/todos", GetTodos)
+		v1.HEAD("/todos", HeadTodos)
+		v1.GET("/todos/calendar.ics", GetTodosCalendar)
+		v1.GET("/todos/stats", GetTodoStats)
+		v1.GET("/todos/export", ExportTodos)
+		v1.GET("/todos/random", GetRandomTodo)
+		v1.GET("/todos/today", GetFocusTodos)
+		v1.GET("/todos/upcoming", GetUpcomingTodos)
+		v1.GET("/todos/completions", GetCompletions)
+		v1.GET("/todos/activity", GetActivity)
+		v1.GET("/todos/changes", GetChanges)
 		v1.GET("/todos/:id", GetTodo)
+		v1.HEAD("/todos/:id", headOf(GetTodo))
 		v1.PUT("/todos/:id", UpdateTodo)
+		v1.PATCH("/todos/:id", PatchTodo)
 		v1.DELETE("/todos/:id", DeleteTodo)
+		v1.PATCH("/todos/:id/project", MoveTodoProject)
+		v1.PATCH("/todos/:id/progress", UpdateTodoProgress)
+		v1.POST("/todos/:id/snooze", SnoozeTodo)
+		v1.POST("/todos/:id/merge", MergeTodo)
+		v1.GET("/todos/:id/children", GetTodoChildren)
+		v1.GET("/todos/:id/markdown", GetTodoMarkdown)
+		v1.GET("/todos/:id/comments", GetTodoComments)
+		v1.POST("/todos/:id/comments", AddTodoComment)
 	}
 
+	// Admin routes, only registered when explicitly enabled
+	if cfg.AdminEnabled {
+		admin := v1.Group("/admin", requireAdminKey())
+		{
+			admin.POST("/reset", ResetTodos)
+			admin.GET("/webhooks", ListWebhooks)
+			admin.POST("/webhooks", RegisterWebhook)
+		}
+	}
+
+	// Send /todos/ to /todos (and vice versa) with a redirect, and match
+	// paths with repeated slashes, only as configured
+	r.RedirectTrailingSlash = cfg.RedirectTrailingSlash
+	r.RemoveExtraSlash = cfg.RemoveExtraSlash
+
+	// Answer unsupported methods on known paths with 405 and an Allow header
+	r.HandleMethodNotAllowed = true
+	r.NoMethod(methodNotAllowed(r, cfg.CORSEnabled))
+
 	// Health check endpoint
 	r.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
+		respondJSON(c, http.StatusOK, gin.H{
 			"status":  "healthy",
 			"service": "go-gin-todo-app",
 			"version": "1.0.0",
 		})
 	})
 
-	// Start server on port 8080
-	r.Run(":8080")
+	// Readiness check exercising a store write, read and delete
+	deepHealth := &deepHealthCheck{check: storeRoundTrip, interval: deepHealthInterval}
+	r.GET("/health/deep", deepHealth.Handler)
+
+	// Runtime counters for diagnostics
+	r.GET("/debug/stats", GetDebugStats)
+
+	return r
+}
+
+func main() {
+	cfg, err := loadConfig(os.LookupEnv)
+	if err != nil {
+		log.Fatal(err)
+	}
+	cfg.apply()
+	r := newRouter(cfg)
+
+	// Start server on the configured port, draining requests on SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Purge old completed todos in the background until shutdown
+	if cfg.CompletedRetention > 0 {
+		go runRetention(ctx, cfg.CompletedRetention, cfg.RetentionInterval)
+	}
+
+	// Release memory held by deleted todos in the background until shutdown
+	if cfg.CompactionInterval > 0 {
+		go runCompaction(ctx, cfg.CompactionInterval)
+	}
+
+	if err := runServer(ctx, ":"+cfg.Port, r, cfg.ShutdownTimeout); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalf("server error: %v", err)
+	}
 }
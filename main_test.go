@@ -0,0 +1,226 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	gin.DefaultWriter = io.Discard
+	os.Exit(m.Run())
+}
+
+// newTestRouter loads the configuration from env, applies it, seeds the
+// store with initial and returns the router. The store and settings are
+// package globals, so tests using it must not run in parallel.
+func newTestRouter(t *testing.T, env map[string]string, initial []Todo) *gin.Engine {
+	t.Helper()
+	cfg, err := loadConfig(lookupFrom(env))
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+	cfg.apply()
+
+	todoMu.Lock()
+	seedTodos(initial)
+	todoMu.Unlock()
+	return newRouter(cfg)
+}
+
+// doRequest serves a request through handler and returns the recorded
+// response. A non-empty body is sent as JSON. header lists header names
+// and values in turn.
+func doRequest(handler http.Handler, method, target, body string, header ...string) *httptest.ResponseRecorder {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, target, reader)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+// decodeBody decodes a JSON response body into v, failing the test when it
+// is not valid JSON
+func decodeBody(t *testing.T, w *httptest.ResponseRecorder, v any) {
+	t.Helper()
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("decoding %q: %v", w.Body.String(), err)
+	}
+}
+
+// storedTodos returns a copy of the store
+func storedTodos() []Todo {
+	todoMu.RLock()
+	defer todoMu.RUnlock()
+	return append([]Todo(nil), todos...)
+}
+
+// storedRevision returns the store's revision
+func storedRevision() uint64 {
+	todoMu.RLock()
+	defer todoMu.RUnlock()
+	return revision
+}
+
+func TestCreateAndGetTodo(t *testing.T) {
+	r := newTestRouter(t, nil, nil)
+
+	w := doRequest(r, http.MethodPost, "/api/v1/todos", `{"title":"Write tests"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: status %d, body %s", w.Code, w.Body)
+	}
+	var created Todo
+	decodeBody(t, w, &created)
+	if created.ID != 1 || created.Title != "Write tests" {
+		t.Fatalf("create: got %+v", created)
+	}
+
+	w = doRequest(r, http.MethodGet, "/api/v1/todos/1", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("get: status %d, body %s", w.Code, w.Body)
+	}
+	var fetched Todo
+	decodeBody(t, w, &fetched)
+	if fetched.Title != created.Title {
+		t.Errorf("get: title %q, want %q", fetched.Title, created.Title)
+	}
+}
+
+func TestTimeRemaining(t *testing.T) {
+	if (Todo{}).timeRemaining() != nil {
+		t.Error("todo without a due date has a time remaining")
+	}
+
+	tests := []struct {
+		name     string
+		offset   time.Duration
+		min, max int64
+	}{
+		{"due in an hour", time.Hour, 3590, 3600},
+		{"overdue by an hour", -time.Hour, -3601, -3600},
+	}
+	for _, tt := range tests {
+		due := time.Now().Add(tt.offset)
+		remaining := Todo{DueDate: &due}.timeRemaining()
+		if remaining == nil || *remaining < tt.min || *remaining > tt.max {
+			t.Errorf("%s: time remaining %v, want %d to %d", tt.name, remaining, tt.min, tt.max)
+		}
+	}
+
+	due := time.Now().Add(-time.Minute)
+	data, err := json.Marshal(Todo{ID: 1, DueDate: &due})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if remaining, ok := fields["time_remaining"].(float64); !ok || remaining >= 0 {
+		t.Errorf("overdue todo serialized time_remaining %v, want a negative number", fields["time_remaining"])
+	}
+}
+
+func TestChangedFields(t *testing.T) {
+	due := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	sameDue := due.In(time.FixedZone("UTC+2", 2*60*60))
+	later := due.Add(time.Hour)
+	parent, otherParent := 1, 2
+	base := Todo{ID: 5, Title: "Base", Project: "work", DueDate: &due, ParentID: &parent, Tags: []string{"a"}}
+
+	tests := []struct {
+		name   string
+		change func(*Todo)
+		want   []string
+	}{
+		{"nothing", func(*Todo) {}, []string{}},
+		{"server-set fields", func(td *Todo) { td.UpdatedAt = time.Now(); td.Comments = []Comment{{Text: "x"}} }, []string{}},
+		{"same instant in another zone", func(td *Todo) { td.DueDate = &sameDue }, []string{}},
+		{"equal parent behind another pointer", func(td *Todo) { p := 1; td.ParentID = &p }, []string{}},
+		{"project", func(td *Todo) { td.Project = "home" }, []string{"project"}},
+		{"due date cleared", func(td *Todo) { td.DueDate = nil }, []string{"due_date"}},
+		{"several", func(td *Todo) {
+			td.Title = "New"
+			td.Completed = true
+			td.ParentID = &otherParent
+			td.DueDate = &later
+			td.Tags = []string{"a", "b"}
+		}, []string{"title", "completed", "parent_id", "due_date", "tags"}},
+	}
+	for _, tt := range tests {
+		after := base
+		tt.change(&after)
+		if got := changedFields(base, after); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: changedFields = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMoveToSameProjectIsNoOp(t *testing.T) {
+	updated := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	r := newTestRouter(t, map[string]string{"LIST_CACHE_TTL": "1m"}, []Todo{{ID: 1, Title: "First", Project: "work", UpdatedAt: updated}})
+	start := storedRevision()
+
+	w := doRequest(r, http.MethodPatch, "/api/v1/todos/1/project", `{"project":"work"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, body %s", w.Code, w.Body)
+	}
+	if got := storedTodos()[0].UpdatedAt; !got.Equal(updated) {
+		t.Errorf("updated_at moved to %v", got)
+	}
+	if storedRevision() != start {
+		t.Error("no-op move published a change")
+	}
+
+	doRequest(r, http.MethodPatch, "/api/v1/todos/1/project", `{"project":"home"}`)
+	if todo := storedTodos()[0]; todo.Project != "home" || !todo.UpdatedAt.After(updated) || storedRevision() != start+1 {
+		t.Errorf("real move: %+v at revision %d", todo, storedRevision())
+	}
+}
+
+func TestServerFieldsIgnoredFromClients(t *testing.T) {
+	created := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	r := newTestRouter(t, nil, []Todo{{ID: 1, Title: "First", CreatedAt: created, UpdatedAt: created}})
+	bogus := `"id":99,"created_at":"1999-01-01T00:00:00Z","updated_at":"1999-01-01T00:00:00Z"`
+
+	start := time.Now()
+	w := doRequest(r, http.MethodPost, "/api/v1/todos", `{"title":"Second",`+bogus+`}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: status %d, body %s", w.Code, w.Body)
+	}
+	var todo Todo
+	decodeBody(t, w, &todo)
+	if todo.ID != 2 || todo.CreatedAt.Before(start) || todo.UpdatedAt.Before(start) {
+		t.Errorf("create: ID %d, created_at %v, updated_at %v", todo.ID, todo.CreatedAt, todo.UpdatedAt)
+	}
+
+	w = doRequest(r, http.MethodPut, "/api/v1/todos/1", `{"title":"Renamed",`+bogus+`}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("update: status %d, body %s", w.Code, w.Body)
+	}
+	decodeBody(t, w, &todo)
+	if todo.ID != 1 || !todo.CreatedAt.Equal(created) || todo.UpdatedAt.Before(start) {
+		t.Errorf("update: ID %d, created_at %v, updated_at %v", todo.ID, todo.CreatedAt, todo.UpdatedAt)
+	}
+	if stored := storedTodos(); len(stored) != 2 || !stored[0].CreatedAt.Equal(created) {
+		t.Errorf("stored %+v", stored)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mdEscapeInline escapes characters that would otherwise be read as inline
+// markdown formatting and folds newlines into spaces
+func mdEscapeInline(value string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		"*", `\*`,
+		"_", `\_`,
+		"`", "\\`",
+		"[", `\[`,
+		"]", `\]`,
+		"#", `\#`,
+		"\r\n", " ",
+		"\n", " ",
+	)
+	return replacer.Replace(value)
+}
+
+// renderTodoMarkdown formats a todo as a shareable markdown document
+func renderTodoMarkdown(todo Todo) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", mdEscapeInline(todo.Title))
+
+	if todo.Description != "" {
+		b.WriteString(strings.TrimSpace(todo.Description))
+		b.WriteString("\n\n")
+	}
+
+	check := " "
+	if todo.Completed {
+		check = "x"
+	}
+	fmt.Fprintf(&b, "- [%s] Completed\n", check)
+	if todo.DueDate != nil {
+		fmt.Fprintf(&b, "- **Due:** %s\n", todo.DueDate.UTC().Format("2006-01-02 15:04 UTC"))
+	}
+	if todo.Priority != "" {
+		fmt.Fprintf(&b, "- **Priority:** %s\n", todo.Priority)
+	}
+	if len(todo.Tags) > 0 {
+		tags := make([]string, len(todo.Tags))
+		for i, tag := range todo.Tags {
+			tags[i] = "`" + tag + "`"
+		}
+		fmt.Fprintf(&b, "- **Tags:** %s\n", strings.Join(tags, " "))
+	}
+	return b.String()
+}
+
+// GetTodoMarkdown returns a single todo rendered as markdown
+func GetTodoMarkdown(c *gin.Context) {
+	id, ok := parseTodoID(c)
+	if !ok {
+		return
+	}
+
+	todoMu.RLock()
+	defer todoMu.RUnlock()
+
+	i := findTodoIndex(id)
+	if i == -1 {
+		respondJSON(c, http.StatusNotFound, gin.H{"error": "Todo not found"})
+		return
+	}
+
+	c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(renderTodoMarkdown(todos[i])))
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// maskFields copies each client-settable field, keyed by its JSON name,
+// from src to dst
+var maskFields = map[string]func(dst *Todo, src Todo){
+	"title":       func(dst *Todo, src Todo) { dst.Title = src.Title },
+	"description": func(dst *Todo, src Todo) { dst.Description = src.Description },
+	"completed":   func(dst *Todo, src Todo) { dst.Completed = src.Completed },
+	"parent_id":   func(dst *Todo, src Todo) { dst.ParentID = src.ParentID },
+	"project":     func(dst *Todo, src Todo) { dst.Project = src.Project },
+	"progress":    func(dst *Todo, src Todo) { dst.Progress = src.Progress },
+	"due_date":    func(dst *Todo, src Todo) { dst.DueDate = src.DueDate },
+	"priority":    func(dst *Todo, src Todo) { dst.Priority = src.Priority },
+	"tags":        func(dst *Todo, src Todo) { dst.Tags = src.Tags },
+}
+
+// parseUpdateMask returns the fields an update applies, from the
+// comma-separated update_mask query parameter or else the body's
+// update_mask. It returns nil when neither is given, meaning every field
+// applies.
+func parseUpdateMask(c *gin.Context, bodyMask []string) (map[string]bool, error) {
+	fields := bodyMask
+	if param, ok := c.GetQuery("update_mask"); ok {
+		fields = strings.Split(param, ",")
+	} else if bodyMask == nil {
+		return nil, nil
+	}
+
+	mask := map[string]bool{}
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if _, ok := maskFields[field]; !ok {
+			names := make([]string, 0, len(maskFields))
+			for name := range maskFields {
+				names = append(names, name)
+			}
+			slices.Sort(names)
+			return nil, fmt.Errorf("unknown update_mask field %q: must be one of %s", field, strings.Join(names, ", "))
+		}
+		mask[field] = true
+	}
+	if len(mask) == 0 {
+		return nil, errors.New("update_mask must list at least one field")
+	}
+	return mask, nil
+}
+
+// applyUpdateMask returns current with the fields in mask taken from update
+func applyUpdateMask(current, update Todo, mask map[string]bool) Todo {
+	for field := range mask {
+		maskFields[field](&current, update)
+	}
+	return current
+}
+
+// ruleErrorsInMask returns the failed binding rules of the fields in mask,
+// or of every field when mask is nil, and nil when there are none
+func ruleErrorsInMask(errs validator.ValidationErrors, mask map[string]bool) error {
+	var kept validator.ValidationErrors
+	for _, fe := range errs {
+		if mask == nil || mask[inputFieldName(fe.StructField())] {
+			kept = append(kept, fe)
+		}
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	return kept
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MergeRequest is the body accepted when merging one todo into another
+type MergeRequest struct {
+	Into *int `json:"into" binding:"required,gt=0"`
+}
+
+// mergeTodos folds source into target. Descriptions are joined, tags and
+// comments are combined, and fields target leaves empty are taken from
+// source; everything else keeps target's value.
+func mergeTodos(target, source Todo) Todo {
+	merged := target
+
+	descriptions := []string{}
+	for _, d := range []string{target.Description, source.Description} {
+		if d = strings.TrimSpace(d); d != "" && !slices.Contains(descriptions, d) {
+			descriptions = append(descriptions, d)
+		}
+	}
+	merged.Description = strings.Join(descriptions, "\n\n")
+
+	merged.Tags = slices.Clone(target.Tags)
+	for _, tag := range source.Tags {
+		if !slices.Contains(merged.Tags, tag) {
+			merged.Tags = append(merged.Tags, tag)
+		}
+	}
+
+	merged.Comments = append(slices.Clone(target.Comments), source.Comments...)
+	slices.SortStableFunc(merged.Comments, func(a, b Comment) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	if merged.DueDate == nil {
+		merged.DueDate = source.DueDate
+	}
+	if merged.Priority == "" {
+		merged.Priority = source.Priority
+	}
+	if merged.Project == "" {
+		merged.Project = source.Project
+	}
+	return merged
+}
+
+// MergeTodo merges the todo into the one named by into, moves its subtasks
+// under that todo, and deletes it
+func MergeTodo(c *gin.Context) {
+	id, ok := parseTodoID(c)
+	if !ok {
+		return
+	}
+
+	var req MergeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondJSON(c, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	intoID := *req.Into
+	if intoID == id {
+		respondJSON(c, http.StatusBadRequest, gin.H{"error": "Cannot merge a todo into itself"})
+		return
+	}
+
+	todoMu.Lock()
+	defer todoMu.Unlock()
+
+	sourceIndex := findTodoIndex(id)
+	if sourceIndex == -1 {
+		respondJSON(c, http.StatusNotFound, gin.H{"error": "Todo not found"})
+		return
+	}
+	targetIndex := findTodoIndex(intoID)
+	if targetIndex == -1 {
+		respondJSON(c, http.StatusNotFound, gin.H{"error": "Target todo not found"})
+		return
+	}
+	if descendantIDs(id)[intoID] {
+		respondJSON(c, http.StatusBadRequest, gin.H{"error": "Cannot merge a todo into one of its subtasks"})
+		return
+	}
+
+	source := todos[sourceIndex]
+	merged := mergeTodos(todos[targetIndex], source)
+	merged.UpdatedAt = time.Now()
+	// Comments are not among the fields editsLocked compares, but taking
+	// the source's comments still edits the target
+	target := todos[targetIndex]
+	if editsLocked(target, merged) || (lockCompleted && target.Completed && len(source.Comments) > 0) {
+		respondJSON(c, http.StatusConflict, gin.H{"error": completedLockedMessage})
+		return
+	}
+	// Moving a subtask under the target edits its parent_id
+	for _, todo := range todos {
+		if todo.ParentID != nil && *todo.ParentID == id {
+			moved := todo
+			moved.ParentID = &merged.ID
+			if editsLocked(todo, moved) {
+				respondJSON(c, http.StatusConflict, gin.H{"error": completedLockedMessage})
+				return
+			}
+		}
+	}
+
+	if isDryRun(c) {
+		respondJSON(c, http.StatusOK, gin.H{"dry_run": true, "todo": merged})
+		return
+	}
+
+	remaining := []Todo{}
+	for _, todo := range todos {
+		switch {
+		case todo.ID == id:
+			continue
+		case todo.ID == intoID:
+			todo = merged
+		case todo.ParentID != nil && *todo.ParentID == id:
+			todo.ParentID = &merged.ID
+			todo.UpdatedAt = merged.UpdatedAt
+			publishChange(eventTodoUpdated, todo)
+		}
+		remaining = append(remaining, todo)
+	}
+	todos = remaining
+	invalidateListCache()
+	publishChange(eventTodoDeleted, source)
+	publishChange(eventTodoUpdated, merged)
+
+	respondJSON(c, http.StatusOK, merged)
+}
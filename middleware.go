@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestIDKey is the context key the request ID is stored under
+const requestIDKey = "request_id"
+
+// maxRequestIDLength bounds the client-supplied request IDs that are kept
+const maxRequestIDLength = 128
+
+// newRequestID returns a random 16-byte ID in hex
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
+}
+
+// assignRequestID takes the request ID from the header named header, or
+// generates one when it is missing or too long, stores it in the context
+// and echoes it in the same response header
+func assignRequestID(header string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := strings.TrimSpace(c.GetHeader(header))
+		if id == "" || len(id) > maxRequestIDLength {
+			id = newRequestID()
+		}
+		c.Set(requestIDKey, id)
+		c.Header(header, id)
+		c.Next()
+	}
+}
+
+// logLine formats gin's access log line, with the request ID appended
+func logLine(param gin.LogFormatterParams) string {
+	var statusColor, methodColor, resetColor string
+	if param.IsOutputColor() {
+		statusColor = param.StatusCodeColor()
+		methodColor = param.MethodColor()
+		resetColor = param.ResetColor()
+	}
+	if param.Latency > time.Minute {
+		param.Latency = param.Latency.Truncate(time.Second)
+	}
+	return fmt.Sprintf("[GIN] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v request_id=%s\n%s",
+		param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+		statusColor, param.StatusCode, resetColor,
+		param.Latency,
+		param.ClientIP,
+		methodColor, param.Method, resetColor,
+		param.Path,
+		param.Keys[requestIDKey],
+		param.ErrorMessage,
+	)
+}
+
+// logSlowRequests logs a warning for every request that takes longer than
+// threshold to handle
+func logSlowRequests(threshold time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		if elapsed := time.Since(start); elapsed > threshold {
+			log.Printf("WARN slow request: %s %s took %s (threshold %s) request_id=%s",
+				c.Request.Method, c.Request.URL.Path, elapsed, threshold, c.GetString(requestIDKey))
+		}
+	}
+}
+
+// cors allows cross-origin requests from allowedOrigins, where "*" allows
+// any origin. Preflight responses may be cached by browsers for maxAge, and
+// exposeHeaders lists the response headers scripts are allowed to read.
+func cors(allowedOrigins []string, maxAge time.Duration, exposeHeaders []string, requestIDHeader string) gin.HandlerFunc {
+	anyOrigin := slices.Contains(allowedOrigins, "*")
+	exposed := strings.Join(exposeHeaders, ", ")
+	return func(c *gin.Context) {
+		if anyOrigin {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Header("Vary", "Origin")
+			if origin := c.GetHeader("Origin"); slices.Contains(allowedOrigins, origin) {
+				c.Header("Access-Control-Allow-Origin", origin)
+			}
+		}
+		c.Header("Access-Control-Allow-Methods", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-Page-Size, X-Envelope, "+requestIDHeader)
+		if exposed != "" {
+			c.Header("Access-Control-Expose-Headers", exposed)
+		}
+		if c.Request.Method == http.MethodOptions {
+			c.Header("Access-Control-Max-Age", strconv.Itoa(int(maxAge/time.Second)))
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		c.Next()
+	}
+}
+
+// jsonDepthExceeds reports whether arrays and objects in data nest deeper
+// than limit, ignoring brackets inside strings. Malformed JSON is left for
+// the decoder to reject.
+func jsonDepthExceeds(data []byte, limit int) bool {
+	depth := 0
+	inString, escaped := false, false
+	for _, b := range data {
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case b == '\\':
+				escaped = true
+			case b == '"':
+				inString = false
+			}
+			continue
+		}
+		switch b {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+			if depth > limit {
+				return true
+			}
+		case '}', ']':
+			depth--
+		}
+	}
+	return false
+}
+
+// limitJSONDepth answers 400 Bad Request to write requests whose body nests
+// deeper than limit, and 413 Request Entity Too Large to those whose body
+// is longer than maxBytes, before any handler decodes it
+func limitJSONDepth(limit, maxBytes int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			c.Next()
+			return
+		}
+		if c.Request.Body == nil {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, int64(maxBytes)))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondJSON(c, http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("request body exceeds %d bytes", maxBytes)})
+			c.Abort()
+			return
+		}
+		if err != nil {
+			respondJSON(c, http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+			c.Abort()
+			return
+		}
+		if jsonDepthExceeds(body, limit) {
+			respondJSON(c, http.StatusBadRequest, gin.H{"error": fmt.Sprintf("request body nests deeper than %d levels", limit)})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Next()
+	}
+}
+
+// routeMatches reports whether a request path matches a registered route
+// pattern, treating :name segments as wildcards and *name as a catch-all
+func routeMatches(pattern, path string) bool {
+	patternParts := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathParts := strings.Split(strings.Trim(path, "/"), "/")
+	for i, part := range patternParts {
+		if strings.HasPrefix(part, "*") {
+			return true
+		}
+		if i >= len(pathParts) {
+			return false
+		}
+		if !strings.HasPrefix(part, ":") && part != pathParts[i] {
+			return false
+		}
+	}
+	return len(patternParts) == len(pathParts)
+}
+
+// methodNotAllowed answers requests for a known path with an unsupported
+// method, listing the methods the path does support in the Allow header.
+// OPTIONS is listed when answersOptions is set, as it is when the CORS
+// middleware answers preflights on every path.
+func methodNotAllowed(r *gin.Engine, answersOptions bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		allowed := []string{}
+		if answersOptions {
+			allowed = append(allowed, http.MethodOptions)
+		}
+		for _, route := range r.Routes() {
+			if routeMatches(route.Path, c.Request.URL.Path) && !slices.Contains(allowed, route.Method) {
+				allowed = append(allowed, route.Method)
+			}
+		}
+		slices.Sort(allowed)
+
+		c.Header("Allow", strings.Join(allowed, ", "))
+		respondJSON(c, http.StatusMethodNotAllowed, gin.H{"error": "Method not allowed"})
+	}
+}
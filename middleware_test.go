@@ -0,0 +1,48 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMethodNotAllowedListsOptionsWithCORS(t *testing.T) {
+	tests := []struct {
+		cors       string
+		allow      string
+		optionsErr bool
+	}{
+		{"true", "DELETE, GET, HEAD, OPTIONS, PATCH, PUT", false},
+		{"false", "DELETE, GET, HEAD, PATCH, PUT", true},
+	}
+	for _, tt := range tests {
+		r := newTestRouter(t, map[string]string{"CORS_ENABLED": tt.cors}, []Todo{{ID: 1, Title: "First"}})
+
+		w := doRequest(r, http.MethodPost, "/api/v1/todos/1", `{}`)
+		if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != tt.allow {
+			t.Errorf("CORS_ENABLED=%s: status %d, Allow %q, want 405 and %q", tt.cors, w.Code, w.Header().Get("Allow"), tt.allow)
+		}
+
+		w = doRequest(r, http.MethodOptions, "/api/v1/todos/1", "")
+		if refused := w.Code == http.StatusMethodNotAllowed; refused != tt.optionsErr {
+			t.Errorf("CORS_ENABLED=%s: OPTIONS status %d", tt.cors, w.Code)
+		}
+	}
+}
+
+func TestLimitJSONDepthCapsBodySize(t *testing.T) {
+	r := newTestRouter(t, map[string]string{"MAX_BODY_BYTES": "64"}, nil)
+
+	w := doRequest(r, http.MethodPost, "/api/v1/todos", `{"title":"`+strings.Repeat("x", 64)+`"}`)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("oversized body: status %d, body %s", w.Code, w.Body)
+	}
+	if len(storedTodos()) != 0 {
+		t.Error("oversized body was stored")
+	}
+
+	w = doRequest(r, http.MethodPost, "/api/v1/todos", `{"title":"Short"}`)
+	if w.Code != http.StatusCreated {
+		t.Errorf("small body: status %d, body %s", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Page is one page of a list along with its pagination metadata
+type Page[T any] struct {
+	Items       []T
+	TotalCount  int
+	CurrentPage int
+	TotalPages  int
+	PerPage     int
+	HasNext     bool
+	HasPrev     bool
+}
+
+// pageParams reads the page and limit query parameters, falling back to
+// the first page and the default page size for missing or invalid values
+// and capping limit at the maximum page size. An X-Page-Size header stands
+// in for a missing limit parameter. The limit is always at least 1, and
+// page is capped so that page*limit cannot overflow.
+func pageParams(c *gin.Context) (page, limit int) {
+	page = 1
+	limit = max(defaultPageSize, 1)
+
+	limitParam := c.Query("limit")
+	if limitParam == "" {
+		limitParam = c.GetHeader("X-Page-Size")
+	}
+	if limitParam != "" {
+		if l, err := strconv.Atoi(limitParam); err == nil && l > 0 {
+			limit = min(l, maxPageSize)
+		}
+	}
+
+	if pageParam := c.Query("page"); pageParam != "" {
+		if p, err := strconv.Atoi(pageParam); err == nil && p > 0 {
+			page = min(p, math.MaxInt/limit)
+		}
+	}
+	return page, limit
+}
+
+// newPage wraps items, already cut to the requested page, with metadata
+// for a list of total items. A limit below 1 is treated as 1.
+func newPage[T any](items []T, total, page, limit int) Page[T] {
+	limit = max(limit, 1)
+	totalPages := (total + limit - 1) / limit
+	if totalPages == 0 {
+		totalPages = 1
+	}
+	if items == nil {
+		items = []T{}
+	}
+	return Page[T]{
+		Items:       items,
+		TotalCount:  total,
+		CurrentPage: page,
+		TotalPages:  totalPages,
+		PerPage:     limit,
+		HasNext:     page < totalPages,
+		HasPrev:     page > 1,
+	}
+}
+
+// paginate returns the given page of items. Pages past the end are empty,
+// however large page is. A limit below 1 is treated as 1.
+func paginate[T any](items []T, page, limit int) Page[T] {
+	limit = max(limit, 1)
+	var window []T
+	// Compare page numbers before multiplying so a huge page cannot
+	// overflow the offset
+	if page >= 1 && page-1 < (len(items)+limit-1)/limit {
+		offset := (page - 1) * limit
+		window = items[offset:min(offset+limit, len(items))]
+	}
+	return newPage(window, len(items), page, limit)
+}
+
+// listMetadataKeys are the keys list responses use besides the one the
+// items are under, which LIST_RESPONSE_KEY must not clash with
+var listMetadataKeys = []string{
+	"total_count", "total_is_estimate", "current_page", "total_pages", "per_page",
+	"has_next", "has_prev", "search_matched", "missing_ids", "days",
+}
+
+// response renders the page in the list response shape, with the items
+// under key
+func (p Page[T]) response(key string) gin.H {
+	return gin.H{
+		key:            p.Items,
+		"total_count":  p.TotalCount,
+		"current_page": p.CurrentPage,
+		"total_pages":  p.TotalPages,
+		"per_page":     p.PerPage,
+		"has_next":     p.HasNext,
+		"has_prev":     p.HasPrev,
+	}
+}
+
+// Shapes of the list response, loaded from LIST_SHAPE. The object shape
+// holds the items and pagination metadata; the array shape is the bare
+// items, with the metadata in headers.
+const (
+	listShapeObject = "object"
+	listShapeArray  = "array"
+)
+
+// listShapeDefault is the list response shape used unless a request asks
+// for the other through its Accept profile
+var listShapeDefault = listShapeObject
+
+// parseListShape validates a LIST_SHAPE value
+func parseListShape(value string) (string, error) {
+	switch value {
+	case listShapeObject, listShapeArray:
+		return value, nil
+	default:
+		return "", fmt.Errorf("invalid LIST_SHAPE %q: must be object or array", value)
+	}
+}
+
+// wantsBareList reports whether the list should be answered as a bare
+// array, asked for with Accept: application/json; profile="array" or by
+// LIST_SHAPE=array unless the request asks for profile="object". XML
+// always gets the object shape, as a document needs a single root.
+func wantsBareList(c *gin.Context) bool {
+	if wantsXML(c) {
+		return false
+	}
+	if acceptsProfile(c, listShapeArray) {
+		return true
+	}
+	return listShapeDefault == listShapeArray && !acceptsProfile(c, listShapeObject)
+}
+
+// metadataHeader returns the header a list metadata key is sent in by the
+// array shape, such as X-Total-Count for total_count
+func metadataHeader(key string) string {
+	words := strings.Split(key, "_")
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return "X-" + strings.Join(words, "-")
+}
+
+// respondBareList answers with the items of a list response as a bare
+// array, moving each metadata field into its header
+func respondBareList(c *gin.Context, response gin.H) {
+	for key, value := range response {
+		if key != listKey {
+			c.Header(metadataHeader(key), fmt.Sprint(value))
+		}
+	}
+	respondJSON(c, http.StatusOK, response[listKey])
+}
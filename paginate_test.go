@@ -0,0 +1,167 @@
+package main
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPaginate(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	tests := []struct {
+		name        string
+		items       []int
+		page, limit int
+		want        []int
+		totalPages  int
+		hasNext     bool
+	}{
+		{"empty", []int{}, 1, 2, []int{}, 1, false},
+		{"exact fit", []int{1, 2, 3, 4}, 2, 2, []int{3, 4}, 2, false},
+		{"first page", items, 1, 2, []int{1, 2}, 3, true},
+		{"last partial page", items, 3, 2, []int{5}, 3, false},
+		{"past the end", items, 4, 2, []int{}, 3, false},
+		{"huge page", items, math.MaxInt, 100, []int{}, 1, false},
+		{"huge page and limit", items, math.MaxInt, math.MaxInt, []int{}, 1, false},
+		{"limit below 1", items, 2, 0, []int{2}, 5, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := paginate(tt.items, tt.page, tt.limit)
+			if !slices.Equal(p.Items, tt.want) {
+				t.Errorf("Items = %v, want %v", p.Items, tt.want)
+			}
+			if p.TotalCount != len(tt.items) || p.TotalPages != tt.totalPages || p.HasNext != tt.hasNext {
+				t.Errorf("TotalCount, TotalPages, HasNext = %d, %d, %t, want %d, %d, %t",
+					p.TotalCount, p.TotalPages, p.HasNext, len(tt.items), tt.totalPages, tt.hasNext)
+			}
+		})
+	}
+}
+
+func TestPageParams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		query       string
+		page, limit int
+	}{
+		{"", 1, max(defaultPageSize, 1)},
+		{"page=3&limit=5", 3, 5},
+		{"page=0&limit=-1", 1, max(defaultPageSize, 1)},
+		{"page=100000000000000001&limit=100", math.MaxInt / 100, 100},
+	}
+	for _, tt := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/todos?"+tt.query, nil)
+		page, limit := pageParams(c)
+		if page != tt.page || limit != tt.limit {
+			t.Errorf("pageParams(%q) = %d, %d, want %d, %d", tt.query, page, limit, tt.page, tt.limit)
+		}
+	}
+}
+
+// seedNumberedTodos fills the store with n todos, every third completed
+func seedNumberedTodos(n int) {
+	seed := make([]Todo, n)
+	for i := range seed {
+		seed[i] = Todo{ID: i + 1, Title: "Todo", Completed: i%3 == 0}
+	}
+	todoMu.Lock()
+	seedTodos(seed)
+	todoMu.Unlock()
+}
+
+func TestPageOfMatchesMatchesPaginate(t *testing.T) {
+	seedNumberedTodos(25)
+	open := func(todo Todo) bool { return !todo.Completed }
+	none := func(Todo) bool { return false }
+
+	todoMu.RLock()
+	defer todoMu.RUnlock()
+	for _, matches := range []func(Todo) bool{nil, open, none} {
+		for _, limit := range []int{1, 4, 10, 30} {
+			for page := 1; page <= 8; page++ {
+				items, total := pageOfMatches(matches, (page-1)*limit, limit)
+				want := paginate(filterTodos(matches), page, limit)
+				if !slices.EqualFunc(items, want.Items, func(a, b Todo) bool { return a.ID == b.ID }) || total != want.TotalCount {
+					t.Errorf("page %d of %d: got %d items of %d, want %d of %d",
+						page, limit, len(items), total, len(want.Items), want.TotalCount)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkListPage(b *testing.B) {
+	seedNumberedTodos(100000)
+	open := func(todo Todo) bool { return !todo.Completed }
+	page, limit := 50, 20
+
+	b.Run("pageOfMatches", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			pageOfMatches(open, (page-1)*limit, limit)
+		}
+	})
+	b.Run("filter then paginate", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			paginate(filterTodos(open), page, limit)
+		}
+	})
+}
+
+func TestBareListShape(t *testing.T) {
+	seed := []Todo{{ID: 1, Title: "First"}, {ID: 2, Title: "Second"}, {ID: 3, Title: "Third"}}
+	tests := []struct {
+		name   string
+		env    map[string]string
+		accept string
+		bare   bool
+	}{
+		{"profile", nil, `application/json; profile="array"`, true},
+		{"LIST_SHAPE", map[string]string{"LIST_SHAPE": "array"}, "", true},
+		{"object profile over LIST_SHAPE", map[string]string{"LIST_SHAPE": "array"}, `application/json; profile="object"`, false},
+		{"default", nil, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRouter(t, tt.env, seed)
+			w := doRequest(r, http.MethodGet, "/api/v1/todos?limit=2", "", "Accept", tt.accept)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status %d, body %s", w.Code, w.Body)
+			}
+			if !tt.bare {
+				var page map[string]any
+				decodeBody(t, w, &page)
+				if _, ok := page["todos"]; !ok || w.Header().Get("X-Total-Count") != "" {
+					t.Errorf("object shape: body %s, X-Total-Count %q", w.Body, w.Header().Get("X-Total-Count"))
+				}
+				return
+			}
+
+			var items []Todo
+			decodeBody(t, w, &items)
+			if len(items) != 2 || items[0].ID != 1 || items[1].ID != 2 {
+				t.Errorf("items %+v", items)
+			}
+			headers := map[string]string{
+				"X-Total-Count":  "3",
+				"X-Current-Page": "1",
+				"X-Total-Pages":  "2",
+				"X-Per-Page":     "2",
+				"X-Has-Next":     "true",
+				"X-Has-Prev":     "false",
+			}
+			for name, want := range headers {
+				if got := w.Header().Get(name); got != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
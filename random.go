@@ -0,0 +1,51 @@
+package main
+
+import (
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// randomTodoRand picks random todos. It can be replaced with a seeded
+// generator for deterministic picks; randomTodoMu guards it since
+// *rand.Rand is not safe for concurrent use.
+var (
+	randomTodoMu   sync.Mutex
+	randomTodoRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// pickRandomTodo returns a uniformly random todo from candidates, which
+// must not be empty
+func pickRandomTodo(candidates []Todo) Todo {
+	randomTodoMu.Lock()
+	defer randomTodoMu.Unlock()
+	return candidates[randomTodoRand.Intn(len(candidates))]
+}
+
+// GetRandomTodo returns a random incomplete todo among those matching the
+// list filters
+func GetRandomTodo(c *gin.Context) {
+	matches, err := todoMatcher(c)
+	if err != nil {
+		respondJSON(c, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	todoMu.RLock()
+	pending := []Todo{}
+	for _, todo := range todos {
+		if !todo.Completed && (matches == nil || matches(todo)) {
+			pending = append(pending, todo)
+		}
+	}
+	todoMu.RUnlock()
+
+	if len(pending) == 0 {
+		respondJSON(c, http.StatusNotFound, gin.H{"error": "No pending todos"})
+		return
+	}
+	respondJSON(c, http.StatusOK, pickRandomTodo(pending))
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"math/rand"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+// seedRandomTodo replaces the random todo generator with one seeded with
+// seed for the rest of the test
+func seedRandomTodo(t *testing.T, seed int64) {
+	randomTodoMu.Lock()
+	previous := randomTodoRand
+	randomTodoRand = rand.New(rand.NewSource(seed))
+	randomTodoMu.Unlock()
+	t.Cleanup(func() {
+		randomTodoMu.Lock()
+		randomTodoRand = previous
+		randomTodoMu.Unlock()
+	})
+}
+
+func TestRandomTodoIsReproducibleWithSeed(t *testing.T) {
+	seed := []Todo{
+		{ID: 1, Title: "One"}, {ID: 2, Title: "Two", Completed: true}, {ID: 3, Title: "Three"},
+		{ID: 4, Title: "Four"}, {ID: 5, Title: "Five"}, {ID: 6, Title: "Six"},
+	}
+	r := newTestRouter(t, nil, seed)
+
+	picks := func() []int {
+		seedRandomTodo(t, 42)
+		ids := []int{}
+		for i := 0; i < 10; i++ {
+			w := doRequest(r, http.MethodGet, "/api/v1/todos/random", "")
+			if w.Code != http.StatusOK {
+				t.Fatalf("status %d, body %s", w.Code, w.Body)
+			}
+			var todo Todo
+			decodeBody(t, w, &todo)
+			if todo.Completed {
+				t.Errorf("picked completed todo %d", todo.ID)
+			}
+			ids = append(ids, todo.ID)
+		}
+		return ids
+	}
+	first, second := picks(), picks()
+	if !slices.Equal(first, second) {
+		t.Errorf("same seed picked %v, then %v", first, second)
+	}
+}
+
+func TestRandomTodoWithoutPending(t *testing.T) {
+	r := newTestRouter(t, nil, []Todo{{ID: 1, Title: "Done", Completed: true}})
+	if w := doRequest(r, http.MethodGet, "/api/v1/todos/random", ""); w.Code != http.StatusNotFound {
+		t.Errorf("status %d, body %s", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// prettyJSONDefault indents JSON responses unless a request opts out,
+// loaded from PRETTY_JSON
+var prettyJSONDefault bool
+
+// wantsPrettyJSON reports whether the response should be indented, using
+// the pretty query parameter when given and the configured default otherwise
+func wantsPrettyJSON(c *gin.Context) bool {
+	if param, ok := c.GetQuery("pretty"); ok {
+		if pretty, err := strconv.ParseBool(param); err == nil {
+			return pretty
+		}
+	}
+	return prettyJSONDefault
+}
+
+// wantsXML reports whether a read request prefers XML over JSON according
+// to its Accept header. Writes always answer in JSON.
+func wantsXML(c *gin.Context) bool {
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		return false
+	}
+	switch c.NegotiateFormat(gin.MIMEJSON, gin.MIMEXML, gin.MIMEXML2) {
+	case gin.MIMEXML, gin.MIMEXML2:
+		return true
+	}
+	return false
+}
+
+// envelopeDefault wraps responses in an envelope unless a request opts
+// out, loaded from RESPONSE_ENVELOPE
+var envelopeDefault bool
+
+// wantsEnvelope reports whether the response should be wrapped, using the
+// X-Envelope header when given and the configured default otherwise
+func wantsEnvelope(c *gin.Context) bool {
+	if header := c.GetHeader("X-Envelope"); header != "" {
+		if wrap, err := strconv.ParseBool(header); err == nil {
+			return wrap
+		}
+	}
+	return envelopeDefault
+}
+
+// envelope wraps the body of a response with the given status in
+// {"success", "data", "error"}. The error message of a failed response
+// moves to error, and any other fields of its body stay under data.
+func envelope(code int, obj any) gin.H {
+	if code < http.StatusBadRequest {
+		return gin.H{"success": true, "data": obj, "error": nil}
+	}
+
+	body, ok := obj.(gin.H)
+	if !ok {
+		return gin.H{"success": false, "data": obj, "error": http.StatusText(code)}
+	}
+	message, ok := body["error"]
+	if !ok {
+		message = http.StatusText(code)
+	}
+	var data any
+	if len(body) > 1 || !ok {
+		rest := gin.H{}
+		for key, value := range body {
+			if key != "error" {
+				rest[key] = value
+			}
+		}
+		data = rest
+	}
+	return gin.H{"success": false, "data": data, "error": message}
+}
+
+// todoListXML is the XML form of a list response: a todos root element
+// holding one todo element per item, followed by the other fields of the
+// response, such as the pagination metadata, in name order
+type todoListXML struct {
+	XMLName xml.Name    `xml:"todos"`
+	Items   any         `xml:"todo"`
+	Fields  []xmlString `xml:",any"`
+}
+
+// xmlString is an element holding a single text value
+type xmlString struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+// xmlList returns obj as a todoListXML when it is a list response, with
+// its todos under listKey, and obj unchanged otherwise
+func xmlList(obj any) any {
+	response, ok := obj.(gin.H)
+	if !ok || response[listKey] == nil || reflect.ValueOf(response[listKey]).Kind() != reflect.Slice {
+		return obj
+	}
+	list := todoListXML{Items: response[listKey]}
+	for key, value := range response {
+		if key != listKey {
+			list.Fields = append(list.Fields, xmlString{XMLName: xml.Name{Local: key}, Value: fmt.Sprint(value)})
+		}
+	}
+	sort.Slice(list.Fields, func(i, j int) bool { return list.Fields[i].XMLName.Local < list.Fields[j].XMLName.Local })
+	return list
+}
+
+// respondJSON renders obj as compact or indented JSON depending on the
+// request, or as XML when the client asks for it, wrapped in an envelope
+// when requested
+func respondJSON(c *gin.Context, code int, obj any) {
+	if wantsXML(c) {
+		obj = xmlList(obj)
+	}
+	if wantsEnvelope(c) {
+		obj = envelope(code, obj)
+	}
+	if wantsXML(c) {
+		body, err := encodeResponse(c, obj)
+		if err != nil {
+			c.String(http.StatusInternalServerError, err.Error())
+			return
+		}
+		c.Data(code, responseContentType(c), body)
+		return
+	}
+	if wantsPrettyJSON(c) {
+		c.IndentedJSON(code, obj)
+		return
+	}
+	c.JSON(code, obj)
+}
+
+// headResponseWriter keeps the status and headers of a response but drops
+// its body
+type headResponseWriter struct {
+	gin.ResponseWriter
+}
+
+func (w headResponseWriter) Write(data []byte) (int, error) {
+	w.WriteHeaderNow()
+	return len(data), nil
+}
+
+func (w headResponseWriter) WriteString(s string) (int, error) {
+	w.WriteHeaderNow()
+	return len(s), nil
+}
+
+// headOf answers HEAD with the status and headers get sends for the same
+// request, without a body
+func headOf(get gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Writer = headResponseWriter{c.Writer}
+		get(c)
+	}
+}
+
+// marshalResponse serializes obj the way respondJSON would render it in a
+// 200 OK response, for handlers that need the body bytes before writing
+// them
+func marshalResponse(c *gin.Context, obj any) ([]byte, error) {
+	if wantsXML(c) {
+		obj = xmlList(obj)
+	}
+	if wantsEnvelope(c) {
+		obj = envelope(http.StatusOK, obj)
+	}
+	return encodeResponse(c, obj)
+}
+
+// encodeResponse serializes obj in the format and indentation the request
+// asks for
+func encodeResponse(c *gin.Context, obj any) ([]byte, error) {
+	if wantsXML(c) {
+		if wantsPrettyJSON(c) {
+			return xml.MarshalIndent(obj, "", "    ")
+		}
+		return xml.Marshal(obj)
+	}
+	if wantsPrettyJSON(c) {
+		return json.MarshalIndent(obj, "", "    ")
+	}
+	return json.Marshal(obj)
+}
+
+// responseContentType returns the Content-Type matching marshalResponse
+func responseContentType(c *gin.Context) string {
+	if wantsXML(c) {
+		return "application/xml; charset=utf-8"
+	}
+	return "application/json; charset=utf-8"
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/xml"
+	"net/http"
+	"testing"
+)
+
+func TestXMLNegotiation(t *testing.T) {
+	r := newTestRouter(t, nil, []Todo{{ID: 1, Title: "First"}, {ID: 2, Title: "Second"}})
+
+	tests := []struct {
+		accept, contentType string
+	}{
+		{"", "application/json; charset=utf-8"},
+		{"application/json", "application/json; charset=utf-8"},
+		{"application/xml", "application/xml; charset=utf-8"},
+		{"text/xml", "application/xml; charset=utf-8"},
+		{"application/json, application/xml", "application/json; charset=utf-8"},
+	}
+	for _, tt := range tests {
+		w := doRequest(r, http.MethodGet, "/api/v1/todos/1", "", "Accept", tt.accept)
+		if got := w.Header().Get("Content-Type"); got != tt.contentType {
+			t.Errorf("Accept %q: Content-Type %q, want %q", tt.accept, got, tt.contentType)
+		}
+	}
+
+	// Writes answer in JSON whatever they accept
+	w := doRequest(r, http.MethodPost, "/api/v1/todos", `{"title":"Third"}`, "Accept", "application/xml")
+	if got := w.Header().Get("Content-Type"); got != "application/json; charset=utf-8" {
+		t.Errorf("create: Content-Type %q", got)
+	}
+}
+
+func TestXMLList(t *testing.T) {
+	r := newTestRouter(t, nil, []Todo{{ID: 1, Title: "First"}, {ID: 2, Title: "Second"}})
+
+	w := doRequest(r, http.MethodGet, "/api/v1/todos", "", "Accept", "application/xml")
+	var list struct {
+		XMLName    xml.Name `xml:"todos"`
+		Todos      []Todo   `xml:"todo"`
+		TotalCount int      `xml:"total_count"`
+		HasNext    bool     `xml:"has_next"`
+	}
+	if err := xml.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatalf("decoding %s: %v", w.Body, err)
+	}
+	if len(list.Todos) != 2 || list.Todos[0].Title != "First" || list.Todos[1].ID != 2 {
+		t.Errorf("todos %+v", list.Todos)
+	}
+	if list.TotalCount != 2 || list.HasNext {
+		t.Errorf("total_count %d, has_next %t", list.TotalCount, list.HasNext)
+	}
+
+	w = doRequest(r, http.MethodGet, "/api/v1/todos/1", "", "Accept", "application/xml")
+	var single struct {
+		XMLName xml.Name `xml:"todo"`
+		Title   string   `xml:"title"`
+	}
+	if err := xml.Unmarshal(w.Body.Bytes(), &single); err != nil || single.Title != "First" {
+		t.Errorf("single todo %s: %v", w.Body, err)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// purgeCompletedBefore permanently removes todos completed before cutoff
+// and returns how many were removed. Todos completed before completion
+// times were recorded fall back to when they were last updated. A todo is kept while any
+// of its descendants is kept, so purging never orphans a child.
+func purgeCompletedBefore(cutoff time.Time) int {
+	todoMu.Lock()
+	defer todoMu.Unlock()
+
+	expired := map[int]bool{}
+	for _, todo := range todos {
+		if !todo.Completed {
+			continue
+		}
+		completedAt := todo.UpdatedAt
+		if todo.CompletedAt != nil {
+			completedAt = *todo.CompletedAt
+		}
+		if completedAt.Before(cutoff) {
+			expired[todo.ID] = true
+		}
+	}
+
+	purge := map[int]bool{}
+	for id := range expired {
+		keep := false
+		for descendant := range descendantIDs(id) {
+			if !expired[descendant] {
+				keep = true
+				break
+			}
+		}
+		if !keep {
+			purge[id] = true
+		}
+	}
+	if len(purge) == 0 {
+		return 0
+	}
+
+	remaining := []Todo{}
+	for _, todo := range todos {
+		if purge[todo.ID] {
+			publishChange(eventTodoDeleted, todo)
+		} else {
+			remaining = append(remaining, todo)
+		}
+	}
+	todos = remaining
+	invalidateListCache()
+	return len(purge)
+}
+
+// runRetention purges expired completed todos every interval until ctx is
+// cancelled
+func runRetention(ctx context.Context, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged := purgeCompletedBefore(time.Now().Add(-retention))
+			log.Printf("Retention: purged %d completed todos older than %s", purged, retention)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestPurgeCompletedBefore(t *testing.T) {
+	now := time.Now()
+	old, recent := now.Add(-48*time.Hour), now.Add(-time.Hour)
+	parent := 5
+	newTestRouter(t, nil, []Todo{
+		// Completed long ago but edited since: completion time decides
+		{ID: 1, Title: "Old, edited", Completed: true, CompletedAt: &old, UpdatedAt: recent},
+		// Completed recently after a long time open
+		{ID: 2, Title: "Recent", Completed: true, CompletedAt: &recent, UpdatedAt: recent},
+		// No completion time recorded: updated_at stands in
+		{ID: 3, Title: "Legacy old", Completed: true, UpdatedAt: old},
+		{ID: 4, Title: "Open", UpdatedAt: old},
+		// Kept because its subtask is kept
+		{ID: 5, Title: "Parent", Completed: true, CompletedAt: &old, UpdatedAt: old},
+		{ID: 6, Title: "Subtask", ParentID: &parent, UpdatedAt: old},
+	})
+
+	if purged := purgeCompletedBefore(now.Add(-24 * time.Hour)); purged != 2 {
+		t.Errorf("purged %d todos, want 2", purged)
+	}
+	var ids []int
+	for _, todo := range storedTodos() {
+		ids = append(ids, todo.ID)
+	}
+	if want := []int{2, 4, 5, 6}; !slices.Equal(ids, want) {
+		t.Errorf("kept %v, want %v", ids, want)
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// revision counts changes to the todo store. It only ever grows, so a
+// client holding an old value knows something changed since. It is guarded
+// by todoMu and bumped once per change by publishChange, or by
+// resetChangeLog when the store is replaced.
+var revision uint64
+
+// revisionETag returns the ETag of the todo collection at rev
+func revisionETag(rev uint64) string {
+	return fmt.Sprintf(`"rev-%d"`, rev)
+}
+
+// collectionNotModified sets the collection's ETag and X-Revision headers
+// and answers 304 Not Modified, reporting true, when If-None-Match names
+// the current revision
+func collectionNotModified(c *gin.Context) bool {
+	todoMu.RLock()
+	rev := revision
+	todoMu.RUnlock()
+
+	etag := revisionETag(rev)
+	c.Header("ETag", etag)
+	c.Header("X-Revision", strconv.FormatUint(rev, 10))
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return true
+	}
+	return false
+}
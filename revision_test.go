@@ -0,0 +1,30 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestListETagRevalidation(t *testing.T) {
+	r := newTestRouter(t, nil, []Todo{{ID: 1, Title: "First"}})
+
+	w := doRequest(r, http.MethodGet, "/api/v1/todos", "")
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || etag == "" {
+		t.Fatalf("list: status %d, ETag %q", w.Code, etag)
+	}
+
+	w = doRequest(r, http.MethodGet, "/api/v1/todos", "", "If-None-Match", etag)
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Fatalf("unchanged list: status %d, body %q", w.Code, w.Body)
+	}
+
+	doRequest(r, http.MethodPost, "/api/v1/todos", `{"title":"Second"}`)
+	w = doRequest(r, http.MethodGet, "/api/v1/todos", "", "If-None-Match", etag)
+	if w.Code != http.StatusOK {
+		t.Errorf("changed list: status %d, want 200", w.Code)
+	}
+	if w.Header().Get("ETag") == etag {
+		t.Error("ETag did not change after a create")
+	}
+}
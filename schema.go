@@ -0,0 +1,124 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// timeType is handled specially since it marshals as an RFC 3339 string
+var timeType = reflect.TypeOf(time.Time{})
+
+// jsonFieldName returns a struct field's JSON name and whether it is
+// omitted when empty, or "" for fields hidden from JSON
+func jsonFieldName(f reflect.StructField) (string, bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = f.Name
+	}
+	return name, strings.Contains(opts, "omitempty")
+}
+
+// applyBindingRules adds the JSON Schema keywords matching a field's
+// binding tag, so the schema reports the same limits validation enforces
+func applyBindingRules(schema gin.H, binding string) {
+	for _, rule := range strings.Split(binding, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "min", "max":
+			n, err := strconv.Atoi(param)
+			if err != nil {
+				continue
+			}
+			keyword := map[string]string{"min": "minimum", "max": "maximum"}[name]
+			if schema["type"] == "string" {
+				keyword = map[string]string{"min": "minLength", "max": "maxLength"}[name]
+			}
+			schema[keyword] = n
+		case "oneof":
+			schema["enum"] = strings.Fields(param)
+		}
+	}
+}
+
+// typeSchema describes a Go type as a JSON Schema fragment
+func typeSchema(t reflect.Type) gin.H {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return gin.H{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Bool:
+		return gin.H{"type": "boolean"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		return gin.H{"type": "integer"}
+	case t.Kind() == reflect.String:
+		return gin.H{"type": "string"}
+	case t.Kind() == reflect.Slice:
+		return gin.H{"type": "array", "items": typeSchema(t.Elem())}
+	case t.Kind() == reflect.Struct:
+		return structSchema(t, nil)
+	}
+	return gin.H{}
+}
+
+// structSchema describes a struct's JSON form. When input is given, its
+// binding rules are applied to the matching fields, fields it lacks are
+// marked read-only and only fields it binds as required are required, so
+// the schema describes what validation accepts. Otherwise fields that are
+// never omitted are required.
+func structSchema(t reflect.Type, input reflect.Type) gin.H {
+	properties := gin.H{}
+	required := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, omitEmpty := jsonFieldName(f)
+		if name == "" || !f.IsExported() {
+			continue
+		}
+
+		schema := typeSchema(f.Type)
+		isRequired := !omitEmpty
+		if input != nil {
+			isRequired = false
+			if inputField, ok := input.FieldByName(f.Name); ok {
+				rules := inputField.Tag.Get("binding")
+				applyBindingRules(schema, rules)
+				isRequired = slices.Contains(strings.Split(rules, ","), "required")
+			} else {
+				schema["readOnly"] = true
+			}
+		}
+		properties[name] = schema
+		if isRequired {
+			required = append(required, name)
+		}
+	}
+	return gin.H{"type": "object", "properties": properties, "required": required}
+}
+
+// todoSchema is the JSON Schema of a todo as the API returns it
+var todoSchema = func() gin.H {
+	schema := structSchema(reflect.TypeOf(Todo{}), reflect.TypeOf(TodoInput{}))
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = "Todo"
+
+	// Added by Todo.MarshalJSON rather than declared on the struct
+	schema["properties"].(gin.H)["time_remaining"] = gin.H{"type": "integer", "readOnly": true}
+	return schema
+}()
+
+// GetTodoSchema returns the JSON Schema of the Todo type
+func GetTodoSchema(c *gin.Context) {
+	respondJSON(c, http.StatusOK, todoSchema)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestTodoSchemaMatchesValidation(t *testing.T) {
+	r := newTestRouter(t, nil, nil)
+
+	var schema struct {
+		Required   []string `json:"required"`
+		Properties map[string]struct {
+			Enum     []string `json:"enum"`
+			ReadOnly bool     `json:"readOnly"`
+		} `json:"properties"`
+	}
+	decodeBody(t, doRequest(r, http.MethodGet, "/api/v1/schema/todo", ""), &schema)
+
+	if !slices.Equal(schema.Required, []string{"title"}) {
+		t.Errorf("required %v, want [title]", schema.Required)
+	}
+	if enum := schema.Properties["priority"].Enum; !slices.Equal(enum, []string{"low", "medium", "high"}) {
+		t.Errorf("priority enum %v", enum)
+	}
+	for _, name := range []string{"id", "created_at", "updated_at"} {
+		if !schema.Properties[name].ReadOnly {
+			t.Errorf("%s is not read-only", name)
+		}
+	}
+
+	// The validation the schema describes
+	if w := doRequest(r, http.MethodPost, "/api/v1/todos", `{"description":"No title"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("create without a title: status %d, body %s", w.Code, w.Body)
+	}
+	if w := doRequest(r, http.MethodPost, "/api/v1/todos", `{"title":"Titled","priority":"urgent"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("create with a priority outside the enum: status %d, body %s", w.Code, w.Body)
+	}
+	if w := doRequest(r, http.MethodPost, "/api/v1/todos", `{"title":"Titled"}`); w.Code != http.StatusCreated {
+		t.Errorf("create with only a title: status %d, body %s", w.Code, w.Body)
+	}
+	// Fields outside an update mask are not checked
+	if w := doRequest(r, http.MethodPut, "/api/v1/todos/1?update_mask=priority", `{"priority":"high"}`); w.Code != http.StatusOK {
+		t.Errorf("masked update without a title: status %d, body %s", w.Code, w.Body)
+	}
+}
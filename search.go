@@ -0,0 +1,59 @@
+package main
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Markers wrapped around matches when search results are highlighted
+const (
+	highlightStart = "<mark>"
+	highlightEnd   = "</mark>"
+)
+
+// searchPattern returns a case-insensitive pattern for the search query
+// parameter, or nil when there is no search
+func searchPattern(c *gin.Context) *regexp.Regexp {
+	term := strings.TrimSpace(c.Query("search"))
+	if term == "" {
+		return nil
+	}
+	return regexp.MustCompile("(?i)" + regexp.QuoteMeta(term))
+}
+
+// searchMatches reports whether the title or description of todo contains
+// a match for pattern
+func searchMatches(todo Todo, pattern *regexp.Regexp) bool {
+	return pattern.MatchString(todo.Title) || pattern.MatchString(todo.Description)
+}
+
+// highlight HTML-escapes text and wraps every match for pattern in
+// highlight markers, so the markers are the only markup in the result
+func highlight(text string, pattern *regexp.Regexp) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range pattern.FindAllStringIndex(text, -1) {
+		b.WriteString(html.EscapeString(text[last:m[0]]))
+		b.WriteString(highlightStart)
+		b.WriteString(html.EscapeString(text[m[0]:m[1]]))
+		b.WriteString(highlightEnd)
+		last = m[1]
+	}
+	b.WriteString(html.EscapeString(text[last:]))
+	return b.String()
+}
+
+// highlightTodos returns copies of items with every match for pattern in
+// their titles and descriptions wrapped in highlight markers
+func highlightTodos(items []Todo, pattern *regexp.Regexp) []Todo {
+	highlighted := make([]Todo, len(items))
+	for i, todo := range items {
+		todo.Title = highlight(todo.Title, pattern)
+		todo.Description = highlight(todo.Description, pattern)
+		highlighted[i] = todo
+	}
+	return highlighted
+}
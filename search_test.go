@@ -0,0 +1,24 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		text, term, want string
+	}{
+		{"Buy milk", "milk", "Buy <mark>milk</mark>"},
+		{"Milk and milk", "milk", "<mark>Milk</mark> and <mark>milk</mark>"},
+		{`<script>alert("milk")</script>`, "milk", `&lt;script&gt;alert(&#34;<mark>milk</mark>&#34;)&lt;/script&gt;`},
+		{"a<b & c", "<b", "a<mark>&lt;b</mark> &amp; c"},
+		{"no match", "milk", "no match"},
+	}
+	for _, tt := range tests {
+		pattern := regexp.MustCompile("(?i)" + regexp.QuoteMeta(tt.term))
+		if got := highlight(tt.text, pattern); got != tt.want {
+			t.Errorf("highlight(%q, %q) = %q, want %q", tt.text, tt.term, got, tt.want)
+		}
+	}
+}
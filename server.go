@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// inFlightRequests counts requests currently being handled
+var inFlightRequests atomic.Int64
+
+// trackInFlight counts the request as in flight until its handlers return
+func trackInFlight() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		inFlightRequests.Add(1)
+		defer inFlightRequests.Add(-1)
+		c.Next()
+	}
+}
+
+// GetDebugStats reports runtime counters useful when diagnosing the server
+func GetDebugStats(c *gin.Context) {
+	respondJSON(c, http.StatusOK, gin.H{
+		"in_flight": inFlightRequests.Load(),
+	})
+}
+
+// startedAt is when the process started. It carries a monotonic clock
+// reading, so uptime is unaffected by wall clock changes.
+var startedAt = time.Now()
+
+// GetServerTime reports the server's current time and uptime, so clients
+// can detect clock skew
+func GetServerTime(c *gin.Context) {
+	respondJSON(c, http.StatusOK, gin.H{
+		"time":           time.Now().UTC().Format(time.RFC3339Nano),
+		"uptime_seconds": time.Since(startedAt).Seconds(),
+	})
+}
+
+// runServer serves handler on addr until ctx is cancelled, then drains
+// in-flight requests for up to timeout before forcing connections closed
+func runServer(ctx context.Context, addr string, handler http.Handler, timeout time.Duration) error {
+	srv := &http.Server{Addr: addr, Handler: handler}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Printf("Shutting down, waiting up to %s for %d in-flight requests", timeout, inFlightRequests.Load())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// Report drain progress while Shutdown waits
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				log.Printf("Draining, %d requests still in flight", inFlightRequests.Load())
+			}
+		}
+	}()
+
+	err := srv.Shutdown(shutdownCtx)
+	close(done)
+	if errors.Is(err, context.DeadlineExceeded) {
+		log.Printf("Shutdown timed out with %d requests in flight, forcing close", inFlightRequests.Load())
+		return srv.Close()
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Println("Server stopped")
+	return nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SnoozeRequest is the body accepted when snoozing a todo by a duration
+type SnoozeRequest struct {
+	Duration string `json:"duration" binding:"required"`
+}
+
+// maxSnoozeDays bounds how far a single snooze can push a due date
+const maxSnoozeDays = 3650
+
+// parseSnoozeDuration parses a positive duration of at most maxSnoozeDays,
+// accepting whole days such as "2d" in addition to Go duration strings
+// such as "90m"
+func parseSnoozeDuration(value string) (time.Duration, error) {
+	var d time.Duration
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q: use whole days such as 1d or a duration such as 90m", value)
+		}
+		// Checked before multiplying, which would overflow for large n
+		if n > maxSnoozeDays {
+			return 0, fmt.Errorf("invalid duration %q: must be at most %dd", value, maxSnoozeDays)
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if d, err = time.ParseDuration(value); err != nil {
+			return 0, fmt.Errorf("invalid duration %q: use whole days such as 1d or a duration such as 90m", value)
+		}
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("invalid duration %q: must be positive", value)
+	}
+	if d > maxSnoozeDays*24*time.Hour {
+		return 0, fmt.Errorf("invalid duration %q: must be at most %dd", value, maxSnoozeDays)
+	}
+	return d, nil
+}
+
+// SnoozeTodo defers a todo's due date, either to the RFC 3339 time in the
+// until query parameter or by the duration in the body. A todo without a
+// due date is snoozed relative to now.
+func SnoozeTodo(c *gin.Context) {
+	id, ok := parseTodoID(c)
+	if !ok {
+		return
+	}
+
+	var until *time.Time
+	var delay time.Duration
+	if untilParam, ok := c.GetQuery("until"); ok {
+		t, err := time.Parse(time.RFC3339, untilParam)
+		if err != nil {
+			respondJSON(c, http.StatusBadRequest, gin.H{"error": "until must be an RFC 3339 time"})
+			return
+		}
+		until = &t
+	} else {
+		var req SnoozeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondJSON(c, http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		var err error
+		if delay, err = parseSnoozeDuration(req.Duration); err != nil {
+			respondJSON(c, http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	todoMu.Lock()
+	defer todoMu.Unlock()
+
+	i := findTodoIndex(id)
+	if i == -1 {
+		respondJSON(c, http.StatusNotFound, gin.H{"error": "Todo not found"})
+		return
+	}
+
+	if until != nil && todos[i].DueDate != nil && until.Before(*todos[i].DueDate) {
+		respondJSON(c, http.StatusBadRequest, gin.H{"error": "until must not be earlier than the current due date"})
+		return
+	}
+	if until == nil {
+		base := time.Now()
+		if todos[i].DueDate != nil {
+			base = *todos[i].DueDate
+		}
+		due := base.Add(delay)
+		until = &due
+	}
+
+	updated := todos[i]
+	updated.DueDate = until
+	updated.UpdatedAt = time.Now()
+	if editsLocked(todos[i], updated) {
+		respondJSON(c, http.StatusConflict, gin.H{"error": completedLockedMessage})
+		return
+	}
+	if isDryRun(c) {
+		respondJSON(c, http.StatusOK, gin.H{"dry_run": true, "todo": updated})
+		return
+	}
+	todos[i] = updated
+	invalidateListCache()
+	publishChange(eventTodoUpdated, todos[i])
+
+	respondJSON(c, http.StatusOK, todos[i])
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseSnoozeDuration(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"1d", 24 * time.Hour, true},
+		{"90m", 90 * time.Minute, true},
+		{"3650d", 3650 * 24 * time.Hour, true},
+		{"3651d", 0, false},
+		{"106751d", 0, false},
+		{"9223372036854775807d", 0, false},
+		{"87601h", 0, false},
+		{"0d", 0, false},
+		{"-2h", 0, false},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := parseSnoozeDuration(tt.value)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("parseSnoozeDuration(%q) = %v, %v", tt.value, got, err)
+		}
+	}
+}
+
+func TestSnoozeUntil(t *testing.T) {
+	due := time.Date(2030, 6, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		until  string
+		status int
+	}{
+		{"2030-06-02T00:00:00Z", http.StatusOK},
+		{"2030-06-01T00:00:00Z", http.StatusOK},
+		{"2030-05-31T00:00:00Z", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		r := newTestRouter(t, nil, []Todo{{ID: 1, Title: "First", DueDate: &due}})
+		w := doRequest(r, http.MethodPost, "/api/v1/todos/1/snooze?until="+tt.until, "")
+		if w.Code != tt.status {
+			t.Errorf("until %s: status %d, want %d, body %s", tt.until, w.Code, tt.status, w.Body)
+		}
+		if w.Code != http.StatusOK && !storedTodos()[0].DueDate.Equal(due) {
+			t.Errorf("until %s: due date moved to %v", tt.until, storedTodos()[0].DueDate)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// todoSortFields maps the sortable field names to their comparison
+var todoSortFields = map[string]func(a, b Todo) int{
+	"id":         func(a, b Todo) int { return cmp.Compare(a.ID, b.ID) },
+	"title":      func(a, b Todo) int { return strings.Compare(a.Title, b.Title) },
+	"progress":   func(a, b Todo) int { return cmp.Compare(a.Progress, b.Progress) },
+	"priority":   func(a, b Todo) int { return cmp.Compare(priorityRanks[a.Priority], priorityRanks[b.Priority]) },
+	"created_at": func(a, b Todo) int { return a.CreatedAt.Compare(b.CreatedAt) },
+	"updated_at": func(a, b Todo) int { return a.UpdatedAt.Compare(b.UpdatedAt) },
+	"due_date":   func(a, b Todo) int { return compareOptionalTimes(a.DueDate, b.DueDate) },
+}
+
+// compareOptionalTimes orders set times before unset ones
+func compareOptionalTimes(a, b *time.Time) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+	return a.Compare(*b)
+}
+
+// sortKey is one field of a sort specification
+type sortKey struct {
+	field string
+	desc  bool
+}
+
+// todoSort is a parsed sort specification: the keys to compare by, in
+// order of precedence
+type todoSort struct {
+	keys []sortKey
+}
+
+// defaultSort is applied when a list request has no sort parameter. It is
+// loaded from DEFAULT_SORT; nil keeps insertion order.
+var defaultSort *todoSort
+
+// parseSort parses a comma-separated list of sort keys, each of the form
+// "field", "-field", "field asc" or "field desc"
+func parseSort(spec string) (*todoSort, error) {
+	s := &todoSort{}
+	for _, part := range strings.Split(spec, ",") {
+		key, err := parseSortKey(part)
+		if err != nil {
+			return nil, err
+		}
+		s.keys = append(s.keys, key)
+	}
+	return s, nil
+}
+
+// parseSortKey parses a single key of a sort specification
+func parseSortKey(spec string) (sortKey, error) {
+	parts := strings.Fields(spec)
+	if len(parts) == 0 || len(parts) > 2 {
+		return sortKey{}, fmt.Errorf("invalid sort %q", spec)
+	}
+
+	key := sortKey{field: parts[0]}
+	if strings.HasPrefix(key.field, "-") {
+		if len(parts) == 2 {
+			return sortKey{}, fmt.Errorf("invalid sort %q", spec)
+		}
+		key.field = key.field[1:]
+		key.desc = true
+	}
+	if len(parts) == 2 {
+		switch strings.ToLower(parts[1]) {
+		case "asc":
+		case "desc":
+			key.desc = true
+		default:
+			return sortKey{}, fmt.Errorf("invalid sort direction %q", parts[1])
+		}
+	}
+
+	if _, ok := todoSortFields[key.field]; !ok {
+		return sortKey{}, fmt.Errorf("unknown sort field %q", key.field)
+	}
+	return key, nil
+}
+
+// sortTodos returns a sorted copy of items, leaving the input untouched.
+// Todos that tie on every key are ordered by ascending ID, so the order is
+// the same from one request to the next.
+func sortTodos(items []Todo, s *todoSort) []Todo {
+	sorted := slices.Clone(items)
+	slices.SortFunc(sorted, func(a, b Todo) int {
+		for _, key := range s.keys {
+			compare := todoSortFields[key.field]
+			result := compare(a, b)
+			if key.desc {
+				result = compare(b, a)
+			}
+			if result != 0 {
+				return result
+			}
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return sorted
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TodoStats summarizes the todo set
+type TodoStats struct {
+	XMLName   xml.Name `json:"-" xml:"stats"`
+	Total     int      `json:"total" xml:"total"`
+	Completed int      `json:"completed" xml:"completed"`
+	Pending   int      `json:"pending" xml:"pending"`
+}
+
+// etagMatches reports whether an If-None-Match header value matches etag
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// GetTodoStats returns aggregate counts, honoring If-None-Match so pollers
+// get 304 Not Modified while nothing has changed
+func GetTodoStats(c *gin.Context) {
+	todoMu.RLock()
+	defer todoMu.RUnlock()
+
+	var stats TodoStats
+	for _, todo := range todos {
+		stats.Total++
+		if todo.Completed {
+			stats.Completed++
+		}
+	}
+	stats.Pending = stats.Total - stats.Completed
+
+	body, err := marshalResponse(c, stats)
+	if err != nil {
+		respondJSON(c, http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+
+	c.Header("ETag", etag)
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, responseContentType(c), body)
+}
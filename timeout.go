@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// timeoutWriter buffers a handler's response so it can be discarded if the
+// handler overruns its deadline. Writes after the timeout fail with
+// http.ErrHandlerTimeout.
+type timeoutWriter struct {
+	gin.ResponseWriter
+	mu       sync.Mutex
+	header   http.Header
+	body     bytes.Buffer
+	status   int
+	timedOut bool
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.timedOut && w.status == 0 {
+		w.status = code
+	}
+}
+
+// WriteHeaderNow is a no-op; the status is sent when the buffered response
+// is copied out
+func (w *timeoutWriter) WriteHeaderNow() {}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(data)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush is a no-op; the response is only sent once the handler finishes
+func (w *timeoutWriter) Flush() {}
+
+func (w *timeoutWriter) Status() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+func (w *timeoutWriter) Size() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.status == 0 {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *timeoutWriter) Written() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.status != 0
+}
+
+// requestTimeout responds 503 Service Unavailable when the remaining
+// handlers take longer than timeout, cancelling the request context so they
+// can stop early. The handlers still run to completion before the request
+// is released, but their response is discarded. Routes listed in streamed
+// are passed through without a deadline, since buffering would hold their
+// whole response in memory.
+func requestTimeout(timeout time.Duration, streamed ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if slices.Contains(streamed, c.FullPath()) {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		real := c.Writer
+		tw := &timeoutWriter{ResponseWriter: real, header: http.Header{}}
+		c.Writer = tw
+
+		done := make(chan struct{})
+		var panicked any
+		go func() {
+			defer close(done)
+			defer func() { panicked = recover() }()
+			c.Next()
+		}()
+
+		timedOut := false
+		select {
+		case <-done:
+		case <-ctx.Done():
+			tw.mu.Lock()
+			select {
+			case <-done:
+			default:
+				tw.timedOut = true
+			}
+			timedOut = tw.timedOut
+			tw.mu.Unlock()
+		}
+
+		if timedOut {
+			body, _ := json.Marshal(gin.H{"error": "Request timed out"})
+			real.Header().Set("Content-Type", "application/json; charset=utf-8")
+			real.WriteHeader(http.StatusServiceUnavailable)
+			real.Write(body)
+			real.Flush()
+		}
+
+		// The context must not be reused while handlers still hold it
+		<-done
+		c.Writer = real
+		if panicked != nil {
+			panic(panicked)
+		}
+		if timedOut {
+			return
+		}
+
+		for name, values := range tw.header {
+			real.Header()[name] = values
+		}
+		real.WriteHeader(tw.Status())
+		real.Write(tw.body.Bytes())
+	}
+}
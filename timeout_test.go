@@ -0,0 +1,45 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newTimeoutRouter serves /slow, which takes ten times timeout, and /fast
+// behind requestTimeout
+func newTimeoutRouter(timeout time.Duration) *gin.Engine {
+	r := gin.New()
+	r.Use(requestTimeout(timeout))
+	r.GET("/slow", func(c *gin.Context) {
+		time.Sleep(10 * timeout)
+		c.String(http.StatusOK, "too late")
+	})
+	r.GET("/fast", func(c *gin.Context) {
+		c.Header("X-Handler", "fast")
+		c.String(http.StatusAccepted, "done")
+	})
+	return r
+}
+
+func TestRequestTimeoutAnswers503(t *testing.T) {
+	w := doRequest(newTimeoutRouter(10*time.Millisecond), http.MethodGet, "/slow", "")
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status %d, want 503", w.Code)
+	}
+	if got := w.Body.String(); got != `{"error":"Request timed out"}` {
+		t.Errorf("body %s", got)
+	}
+}
+
+func TestRequestTimeoutPassesFastResponses(t *testing.T) {
+	w := doRequest(newTimeoutRouter(time.Second), http.MethodGet, "/fast", "")
+	if w.Code != http.StatusAccepted || w.Body.String() != "done" {
+		t.Fatalf("status %d, body %q", w.Code, w.Body)
+	}
+	if w.Header().Get("X-Handler") != "fast" {
+		t.Error("handler header was not copied out")
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxUpcomingDays bounds the window of an upcoming request
+const maxUpcomingDays = 366
+
+// defaultUpcomingDays is the window used when days is not given
+const defaultUpcomingDays = 7
+
+// upcomingTodos returns the incomplete todos in items due from now up to
+// the end of the day days days after now's, in now's location, sorted by
+// due date and then ID
+func upcomingTodos(items []Todo, now time.Time, days int) []Todo {
+	end := startOfDay(now).AddDate(0, 0, days+1)
+	upcoming := []Todo{}
+	for _, todo := range items {
+		if todo.Completed || todo.DueDate == nil {
+			continue
+		}
+		if todo.DueDate.Before(now) || !todo.DueDate.Before(end) {
+			continue
+		}
+		upcoming = append(upcoming, todo)
+	}
+
+	slices.SortFunc(upcoming, func(a, b Todo) int {
+		if byDue := a.DueDate.Compare(*b.DueDate); byDue != 0 {
+			return byDue
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return upcoming
+}
+
+// upcomingDays parses the days query parameter, which must be a positive
+// integer of at most maxUpcomingDays
+func upcomingDays(c *gin.Context) (int, error) {
+	param := c.Query("days")
+	if param == "" {
+		return defaultUpcomingDays, nil
+	}
+	days, err := strconv.Atoi(param)
+	if err != nil || days < 1 || days > maxUpcomingDays {
+		return 0, fmt.Errorf("invalid days %q: must be an integer from 1 to %d", param, maxUpcomingDays)
+	}
+	return days, nil
+}
+
+// GetUpcomingTodos returns a page of the incomplete todos due between now
+// and the end of the day days days from today, soonest first, with days
+// taken in the time zone named by tz
+func GetUpcomingTodos(c *gin.Context) {
+	days, err := upcomingDays(c)
+	if err != nil {
+		respondJSON(c, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	now, err := requestNow(c)
+	if err != nil {
+		respondJSON(c, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	page, limit := pageParams(c)
+
+	todoMu.RLock()
+	upcoming := upcomingTodos(todos, now, days)
+	todoMu.RUnlock()
+
+	response := paginate(upcoming, page, limit).response(listKey)
+	response["days"] = days
+	respondJSON(c, http.StatusOK, response)
+}
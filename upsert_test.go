@@ -0,0 +1,58 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestPutMissingTodoIsNotFoundByDefault(t *testing.T) {
+	r := newTestRouter(t, nil, nil)
+
+	if w := doRequest(r, http.MethodPut, "/api/v1/todos/5", `{"title":"Nowhere"}`); w.Code != http.StatusNotFound {
+		t.Errorf("status %d, body %s", w.Code, w.Body)
+	}
+	if got := len(storedTodos()); got != 0 {
+		t.Errorf("stored %d todos, want 0", got)
+	}
+}
+
+func TestUpsertCreatesTodo(t *testing.T) {
+	r := newTestRouter(t, nil, nil)
+
+	w := doRequest(r, http.MethodPut, "/api/v1/todos/5?upsert=true", `{"title":"Here"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("upsert: status %d, body %s", w.Code, w.Body)
+	}
+	var created Todo
+	decodeBody(t, w, &created)
+	if created.ID != 5 {
+		t.Errorf("upserted ID %d, want 5", created.ID)
+	}
+
+	// nextID moved past the upserted ID
+	decodeBody(t, doRequest(r, http.MethodPost, "/api/v1/todos", `{"title":"Next"}`), &created)
+	if created.ID != 6 {
+		t.Errorf("next create got ID %d, want 6", created.ID)
+	}
+}
+
+func TestUpsertRejectsIDAtCeiling(t *testing.T) {
+	r := newTestRouter(t, map[string]string{"UPSERT_ON_PUT": "true"}, nil)
+
+	w := doRequest(r, http.MethodPut, "/api/v1/todos/9223372036854775807", `{"title":"Too far"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("upsert at MaxInt: status %d, body %s", w.Code, w.Body)
+	}
+
+	w = doRequest(r, http.MethodPut, "/api/v1/todos/2147483646", `{"title":"Last"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("upsert below ceiling: status %d, body %s", w.Code, w.Body)
+	}
+	w = doRequest(r, http.MethodPost, "/api/v1/todos", `{"title":"No ID left"}`)
+	if w.Code != http.StatusInsufficientStorage {
+		t.Fatalf("create after last ID: status %d, body %s", w.Code, w.Body)
+	}
+	if got := len(storedTodos()); got != 1 {
+		t.Errorf("stored %d todos, want 1", got)
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// fieldError is a validation failure attributed to a single input field,
+// named by its JSON key
+type fieldError struct {
+	field string
+	err   error
+}
+
+func (e *fieldError) Error() string {
+	return e.err.Error()
+}
+
+// unprocessableValidation answers input that is well-formed but fails
+// validation with 422 instead of 400, loaded from UNPROCESSABLE_VALIDATION
+var unprocessableValidation bool
+
+// Units the title and description length limits can be counted in, loaded from
+// LENGTH_MODE
+const (
+	lengthRunes = "runes"
+	lengthBytes = "bytes"
+)
+
+// Length limits on titles and descriptions, loaded from MAX_TITLE_LENGTH,
+// MAX_DESCRIPTION_LENGTH and LENGTH_MODE. A zero limit means no limit.
+var (
+	maxTitleLength       int
+	maxDescriptionLength int
+	lengthMode           = lengthRunes
+)
+
+// parseLengthMode validates a LENGTH_MODE value
+func parseLengthMode(value string) (string, error) {
+	switch value {
+	case lengthRunes, lengthBytes:
+		return value, nil
+	default:
+		return "", fmt.Errorf("invalid LENGTH_MODE %q: must be runes or bytes", value)
+	}
+}
+
+// checkLength returns a fieldError when value is longer than limit,
+// counted in lengthMode's unit
+func checkLength(field, value string, limit int) error {
+	if limit == 0 {
+		return nil
+	}
+	length, unit := utf8.RuneCountInString(value), "characters"
+	if lengthMode == lengthBytes {
+		length, unit = len(value), "bytes"
+	}
+	if length > limit {
+		return &fieldError{field: field, err: fmt.Errorf("%s is %d %s long, exceeding the maximum of %d %s", field, length, unit, limit, unit)}
+	}
+	return nil
+}
+
+// validationStatus returns the status for input that fails validation
+func validationStatus() int {
+	if unprocessableValidation {
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusBadRequest
+}
+
+// bindErrorStatus returns the status for an error from binding a body:
+// validationStatus for failed binding rules and field checks, and 400 for
+// a missing or malformed body
+func bindErrorStatus(err error) int {
+	var ruleErrs validator.ValidationErrors
+	var fe *fieldError
+	if errors.As(err, &ruleErrs) || errors.As(err, &fe) {
+		return validationStatus()
+	}
+	return http.StatusBadRequest
+}
+
+// inputFieldName returns the JSON key of the named TodoInput field
+func inputFieldName(structField string) string {
+	f, ok := reflect.TypeOf(TodoInput{}).FieldByName(structField)
+	if !ok {
+		return strings.ToLower(structField)
+	}
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	return name
+}
+
+// describeRule turns a failed binding rule into a readable message
+func describeRule(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "max":
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", fe.Param())
+	default:
+		return fmt.Sprintf("failed the %s rule", fe.Tag())
+	}
+}
+
+// fieldErrors maps a validation error to messages keyed by JSON field name.
+// Errors that cannot be attributed to a field are reported under "body".
+func fieldErrors(err error) map[string]string {
+	errs := map[string]string{}
+
+	var ruleErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var fe *fieldError
+	switch {
+	case errors.As(err, &ruleErrs):
+		for _, ruleErr := range ruleErrs {
+			errs[inputFieldName(ruleErr.StructField())] = describeRule(ruleErr)
+		}
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		errs[typeErr.Field] = fmt.Sprintf("must be of type %s", typeErr.Type)
+	case errors.As(err, &fe):
+		errs[fe.field] = fe.Error()
+	case strings.HasPrefix(err.Error(), `json: unknown field "`):
+		// encoding/json has no typed error for DisallowUnknownFields
+		name := strings.TrimSuffix(strings.TrimPrefix(err.Error(), `json: unknown field "`), `"`)
+		errs[name] = "is not a known field"
+	default:
+		errs["body"] = err.Error()
+	}
+	return errs
+}
+
+// ValidateTodo runs the create validation against the body without storing
+// anything, so forms can check input before submitting
+func ValidateTodo(c *gin.Context) {
+	newTodo, err := bindNewTodo(c)
+	if err == nil {
+		todoMu.RLock()
+		err = checkNewTodo(newTodo)
+		todoMu.RUnlock()
+	}
+
+	if err != nil {
+		respondJSON(c, http.StatusUnprocessableEntity, gin.H{
+			"valid":  false,
+			"errors": fieldErrors(err),
+		})
+		return
+	}
+	respondJSON(c, http.StatusOK, gin.H{"valid": true})
+}
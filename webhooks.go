@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Webhook event types
+const (
+	eventTodoCreated = "todo.created"
+	eventTodoUpdated = "todo.updated"
+	eventTodoDeleted = "todo.deleted"
+)
+
+// WebhookEvent is the payload POSTed to webhook targets
+type WebhookEvent struct {
+	Type      string    `json:"type"`
+	Todo      Todo      `json:"todo"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// WebhookRequest is the body accepted when registering a webhook target
+type WebhookRequest struct {
+	URL string `json:"url" binding:"required,url"`
+}
+
+// Limits on background webhook delivery
+const (
+	webhookWorkers   = 4
+	webhookQueueSize = 1000
+)
+
+// webhookDelivery is one event waiting to be POSTed to one target
+type webhookDelivery struct {
+	target string
+	body   []byte
+}
+
+// webhookDispatcher delivers events to the registered target URLs in the
+// background, retrying failed deliveries. A fixed pool of workers drains a
+// bounded queue, started on the first event.
+type webhookDispatcher struct {
+	mu         sync.RWMutex
+	urls       []string
+	client     *http.Client
+	maxRetries int
+	retryDelay time.Duration
+
+	start sync.Once
+	queue chan webhookDelivery
+}
+
+// webhooks is the process-wide dispatcher, configured from WEBHOOK_URLS,
+// WEBHOOK_TIMEOUT and WEBHOOK_MAX_RETRIES. Its client only reaches
+// publicly routable addresses.
+var webhooks = &webhookDispatcher{
+	client:     newPublicClient(5 * time.Second),
+	maxRetries: 3,
+	retryDelay: time.Second,
+}
+
+// validHTTPURL reports whether raw is an absolute http or https URL
+func validHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// register adds a target URL, ignoring duplicates
+func (d *webhookDispatcher) register(target string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !slices.Contains(d.urls, target) {
+		d.urls = append(d.urls, target)
+	}
+}
+
+// targets returns a snapshot of the registered URLs
+func (d *webhookDispatcher) targets() []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return slices.Clone(d.urls)
+}
+
+// dispatch queues an event for todo to every target without blocking the
+// caller. Deliveries are dropped, and logged, while the queue is full.
+func (d *webhookDispatcher) dispatch(eventType string, todo Todo) {
+	targets := d.targets()
+	if len(targets) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(WebhookEvent{Type: eventType, Todo: todo, Timestamp: time.Now()})
+	if err != nil {
+		log.Printf("webhook: failed to encode %s event: %v", eventType, err)
+		return
+	}
+	d.start.Do(func() {
+		d.queue = make(chan webhookDelivery, webhookQueueSize)
+		for i := 0; i < webhookWorkers; i++ {
+			go d.work(d.queue)
+		}
+	})
+	for _, target := range targets {
+		select {
+		case d.queue <- webhookDelivery{target: target, body: body}:
+		default:
+			log.Printf("webhook: queue full, dropping %s event for %s", eventType, target)
+		}
+	}
+}
+
+// work delivers queued events one at a time
+func (d *webhookDispatcher) work(queue <-chan webhookDelivery) {
+	for delivery := range queue {
+		d.deliver(delivery.target, delivery.body)
+	}
+}
+
+// deliver POSTs body to target, retrying with a linear backoff until a 2xx
+// response is received or the retries are exhausted
+func (d *webhookDispatcher) deliver(target string, body []byte) {
+	for attempt := 0; attempt <= d.maxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(attempt) * d.retryDelay)
+		}
+
+		resp, err := d.client.Post(target, "application/json", bytes.NewReader(body))
+		if err != nil {
+			log.Printf("webhook: delivery to %s failed (attempt %d): %v", target, attempt+1, err)
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return
+		}
+		log.Printf("webhook: delivery to %s failed (attempt %d): status %d", target, attempt+1, resp.StatusCode)
+	}
+	log.Printf("webhook: giving up on %s after %d attempts", target, d.maxRetries+1)
+}
+
+// ListWebhooks returns the registered webhook target URLs
+func ListWebhooks(c *gin.Context) {
+	respondJSON(c, http.StatusOK, gin.H{"urls": webhooks.targets()})
+}
+
+// RegisterWebhook adds a webhook target URL
+func RegisterWebhook(c *gin.Context) {
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondJSON(c, http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !validHTTPURL(req.URL) {
+		respondJSON(c, http.StatusBadRequest, gin.H{"error": "Webhook URL must be an absolute http or https URL"})
+		return
+	}
+	if checkDestinationURL(req.URL) != nil {
+		respondJSON(c, http.StatusBadRequest, gin.H{"error": "Webhook URL must not point to a loopback, private or link-local address"})
+		return
+	}
+
+	webhooks.register(req.URL)
+	respondJSON(c, http.StatusCreated, gin.H{"urls": webhooks.targets()})
+}
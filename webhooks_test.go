@@ -0,0 +1,89 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRegisterWebhookRefusesPrivateURLs(t *testing.T) {
+	r := newTestRouter(t, map[string]string{"ENABLE_ADMIN": "true", "ADMIN_API_KEY": "secret"}, nil)
+	t.Cleanup(func() {
+		webhooks.mu.Lock()
+		webhooks.urls = nil
+		webhooks.mu.Unlock()
+	})
+
+	for _, target := range []string{"http://127.0.0.1:8080/hook", "http://169.254.169.254/", "http://localhost/hook"} {
+		w := doRequest(r, http.MethodPost, "/api/v1/admin/webhooks", `{"url":"`+target+`"}`, "X-Admin-Key", "secret")
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, body %s", target, w.Code, w.Body)
+		}
+	}
+	if targets := webhooks.targets(); len(targets) != 0 {
+		t.Errorf("registered %v", targets)
+	}
+
+	w := doRequest(r, http.MethodPost, "/api/v1/admin/webhooks", `{"url":"https://example.com/hook"}`, "X-Admin-Key", "secret")
+	if w.Code != http.StatusCreated {
+		t.Errorf("public URL: status %d, body %s", w.Code, w.Body)
+	}
+}
+
+func TestWebhookDeliveryRefusesLoopbackAtDial(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer server.Close()
+
+	d := &webhookDispatcher{client: newPublicClient(time.Second)}
+	d.deliver(server.URL, []byte(`{}`))
+	if hits.Load() != 0 {
+		t.Error("delivery reached a loopback server")
+	}
+}
+
+func TestWebhookWorkersAreBounded(t *testing.T) {
+	const events = 3 * webhookWorkers
+	var inFlight, peak, delivered atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		<-release
+		inFlight.Add(-1)
+		delivered.Add(1)
+	}))
+	defer server.Close()
+
+	d := &webhookDispatcher{client: server.Client()}
+	d.register(server.URL)
+	for i := 0; i < events; i++ {
+		d.dispatch(eventTodoCreated, Todo{ID: i + 1})
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for inFlight.Load() < webhookWorkers && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if got := peak.Load(); got != webhookWorkers {
+		t.Errorf("%d deliveries in flight, want %d", got, webhookWorkers)
+	}
+
+	close(release)
+	for delivered.Load() < events && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := delivered.Load(); got != events {
+		t.Errorf("delivered %d events, want %d", got, events)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// writeJob is a mutating request waiting for the writer goroutine. panicked
+// carries a panic from its handlers back to the request's goroutine.
+type writeJob struct {
+	c        *gin.Context
+	done     chan struct{}
+	panicked any
+}
+
+// run runs the job's remaining handlers, capturing any panic so the writer
+// goroutine survives it
+func (job *writeJob) run() {
+	defer close(job.done)
+	defer func() { job.panicked = recover() }()
+	job.c.Next()
+}
+
+// queueWrites hands POST, PUT, PATCH and DELETE requests to a single writer
+// goroutine that runs their remaining handlers one at a time, in arrival
+// order, so bursts of writes queue up instead of contending for todoMu.
+// At most size requests wait at once; requests arriving at a full queue get
+// 503 Service Unavailable. Other requests are not queued.
+func queueWrites(size int) gin.HandlerFunc {
+	jobs := make(chan *writeJob, size)
+	go func() {
+		for job := range jobs {
+			job.run()
+		}
+	}()
+
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			c.Next()
+			return
+		}
+
+		job := &writeJob{c: c, done: make(chan struct{})}
+		select {
+		case jobs <- job:
+		default:
+			c.Header("Retry-After", "1")
+			respondJSON(c, http.StatusServiceUnavailable, gin.H{"error": "Write queue is full, try again later"})
+			c.Abort()
+			return
+		}
+
+		<-job.done
+		if job.panicked != nil {
+			// Re-raise on the request's goroutine for gin's recovery
+			panic(job.panicked)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestQueueWritesRejectsWhenFull(t *testing.T) {
+	entered := make(chan struct{}, 3)
+	release := make(chan struct{})
+	r := newBlockingRouter(queueWrites(1), entered, release)
+
+	// The first write holds the writer goroutine. Of the next two, one
+	// takes the queue's only slot and the other is turned away.
+	first := serveAsync(r, http.MethodPost, "/block")
+	<-entered
+	second := serveAsync(r, http.MethodPost, "/block")
+	third := serveAsync(r, http.MethodPost, "/block")
+
+	var queued <-chan *httptest.ResponseRecorder
+	var w *httptest.ResponseRecorder
+	select {
+	case w = <-second:
+		queued = third
+	case w = <-third:
+		queued = second
+	case <-time.After(time.Second):
+		t.Fatal("no write was turned away from the full queue")
+	}
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "1" {
+		t.Errorf("write at a full queue: status %d, Retry-After %q", w.Code, w.Header().Get("Retry-After"))
+	}
+
+	close(release)
+	for i, done := range []<-chan *httptest.ResponseRecorder{first, queued} {
+		if w := <-done; w.Code != http.StatusNoContent {
+			t.Errorf("write %d: status %d", i+1, w.Code)
+		}
+	}
+}
+
+func TestQueueWritesSkipsReads(t *testing.T) {
+	entered := make(chan struct{}, 2)
+	release := make(chan struct{})
+	r := newBlockingRouter(queueWrites(1), entered, release)
+
+	write := serveAsync(r, http.MethodPost, "/block")
+	<-entered
+
+	// A read runs alongside the write holding the writer goroutine
+	read := serveAsync(r, http.MethodGet, "/block")
+	select {
+	case <-entered:
+	case <-time.After(time.Second):
+		t.Fatal("read waited for the write queue")
+	}
+
+	close(release)
+	<-write
+	<-read
+}
+
+func TestQueueWritesConcurrentCreates(t *testing.T) {
+	const creates = 100
+	r := newTestRouter(t, map[string]string{"WRITE_QUEUE_SIZE": "128"}, nil)
+
+	var wg sync.WaitGroup
+	responses := make(chan *httptest.ResponseRecorder, creates)
+	for i := 0; i < creates; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			responses <- doRequest(r, http.MethodPost, "/api/v1/todos", `{"title":"queued"}`)
+		}()
+	}
+	wg.Wait()
+	close(responses)
+
+	ids := map[int]bool{}
+	for w := range responses {
+		if w.Code != http.StatusCreated {
+			t.Fatalf("create: status %d, body %s", w.Code, w.Body)
+		}
+		var created Todo
+		decodeBody(t, w, &created)
+		if ids[created.ID] {
+			t.Errorf("ID %d was given out twice", created.ID)
+		}
+		ids[created.ID] = true
+	}
+	if len(ids) != creates || len(storedTodos()) != creates {
+		t.Errorf("%d unique IDs and %d stored todos, want %d", len(ids), len(storedTodos()), creates)
+	}
+}